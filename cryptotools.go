@@ -1,6 +1,8 @@
 package cryptotools
 
 import (
+	"crypto"
+
 	"github.com/howood/cryptotools/pkg/commonkeycrypto"
 	"github.com/howood/cryptotools/pkg/publickeycrypto"
 )
@@ -15,6 +17,74 @@ const (
 	EncryptTypeECDSA publickeycrypto.EncryptKeyType = publickeycrypto.EncryptTypeECDSA
 	// EncryptTypeED25519 is ED25519 KeyType
 	EncryptTypeED25519 publickeycrypto.EncryptKeyType = publickeycrypto.EncryptTypeED25519
+	// EncryptTypeX25519 is X25519 KeyType
+	EncryptTypeX25519 publickeycrypto.EncryptKeyType = publickeycrypto.EncryptTypeX25519
+)
+
+const (
+	// CipherModeAesOfb is AES-OFB CipherMode kept for compatibility
+	CipherModeAesOfb commonkeycrypto.CipherMode = commonkeycrypto.CipherModeAesOfb
+	// CipherModeAesGcm is AES-GCM CipherMode
+	CipherModeAesGcm commonkeycrypto.CipherMode = commonkeycrypto.CipherModeAesGcm
+	// CipherModeXChaCha20Poly1305 is XChaCha20-Poly1305 CipherMode
+	CipherModeXChaCha20Poly1305 commonkeycrypto.CipherMode = commonkeycrypto.CipherModeXChaCha20Poly1305
+	// CipherModeAesSiv is deterministic AES-SIV CipherMode
+	CipherModeAesSiv commonkeycrypto.CipherMode = commonkeycrypto.CipherModeAesSiv
+)
+
+const (
+	// KeyDerivationArgon2id is Argon2id KeyDerivation
+	KeyDerivationArgon2id commonkeycrypto.KeyDerivation = commonkeycrypto.KeyDerivationArgon2id
+	// KeyDerivationScrypt is scrypt KeyDerivation
+	KeyDerivationScrypt commonkeycrypto.KeyDerivation = commonkeycrypto.KeyDerivationScrypt
+	// KeyDerivationPBKDF2 is PBKDF2-SHA256 KeyDerivation
+	KeyDerivationPBKDF2 commonkeycrypto.KeyDerivation = commonkeycrypto.KeyDerivationPBKDF2
+)
+
+const (
+	// EciesKDFX963SHA256 is ANSI X9.63 KDF with SHA-256 for ECIES
+	EciesKDFX963SHA256 publickeycrypto.EciesKDF = publickeycrypto.EciesKDFX963SHA256
+	// EciesKDFHKDFSHA256 is HKDF with SHA-256 for ECIES
+	EciesKDFHKDFSHA256 publickeycrypto.EciesKDF = publickeycrypto.EciesKDFHKDFSHA256
+	// EciesCipherAes256Gcm is AES-256-GCM for ECIES
+	EciesCipherAes256Gcm publickeycrypto.EciesCipher = publickeycrypto.EciesCipherAes256Gcm
+	// EciesCipherAes256CtrHmacSHA256 is AES-256-CTR with HMAC-SHA256 for ECIES
+	EciesCipherAes256CtrHmacSHA256 publickeycrypto.EciesCipher = publickeycrypto.EciesCipherAes256CtrHmacSHA256
+)
+
+const (
+	// RsaSignatureSchemePSS is RSASSA-PSS signature scheme
+	RsaSignatureSchemePSS publickeycrypto.RsaSignatureScheme = publickeycrypto.RsaSignatureSchemePSS
+	// RsaSignatureSchemePKCS1v15 is RSASSA-PKCS1-v1_5 signature scheme
+	RsaSignatureSchemePKCS1v15 publickeycrypto.RsaSignatureScheme = publickeycrypto.RsaSignatureSchemePKCS1v15
+	// EcdsaSignatureFormatASN1 is ASN.1 DER ECDSA signature format
+	EcdsaSignatureFormatASN1 publickeycrypto.EcdsaSignatureFormat = publickeycrypto.EcdsaSignatureFormatASN1
+	// EcdsaSignatureFormatRaw is r || s ECDSA signature format
+	EcdsaSignatureFormatRaw publickeycrypto.EcdsaSignatureFormat = publickeycrypto.EcdsaSignatureFormatRaw
+)
+
+const (
+	// KeyIDStrategyThumbprintSHA256 is RFC 7638 JWK thumbprint with SHA-256 key ID
+	KeyIDStrategyThumbprintSHA256 publickeycrypto.KeyIDStrategy = publickeycrypto.KeyIDStrategyThumbprintSHA256
+	// KeyIDStrategyThumbprintSHA512 is RFC 7638 JWK thumbprint with SHA-512 key ID
+	KeyIDStrategyThumbprintSHA512 publickeycrypto.KeyIDStrategy = publickeycrypto.KeyIDStrategyThumbprintSHA512
+	// KeyIDStrategyLegacyMD5 is MD5 key ID of older versions
+	KeyIDStrategyLegacyMD5 publickeycrypto.KeyIDStrategy = publickeycrypto.KeyIDStrategyLegacyMD5
+)
+
+const (
+	// KeyFormatPEM is PEM key format
+	KeyFormatPEM publickeycrypto.KeyFormat = publickeycrypto.KeyFormatPEM
+	// KeyFormatDER is DER key format
+	KeyFormatDER publickeycrypto.KeyFormat = publickeycrypto.KeyFormatDER
+	// KeyFormatJWK is JWK key format
+	KeyFormatJWK publickeycrypto.KeyFormat = publickeycrypto.KeyFormatJWK
+	// KeyFormatJWKS is JWKS key format
+	KeyFormatJWKS publickeycrypto.KeyFormat = publickeycrypto.KeyFormatJWKS
+	// KeyFormatOpenSSH is OpenSSH key format
+	KeyFormatOpenSSH publickeycrypto.KeyFormat = publickeycrypto.KeyFormatOpenSSH
+	// KeyFormatAuthorizedKey is OpenSSH authorized_keys key format
+	KeyFormatAuthorizedKey publickeycrypto.KeyFormat = publickeycrypto.KeyFormatAuthorizedKey
 )
 
 // NewCommonKeyCrypto create CommonKeyCrypto
@@ -22,8 +92,38 @@ func NewCommonKeyCrypto(commonKey []byte) (*commonkeycrypto.CommonKeyCrypto, err
 	return commonkeycrypto.NewCommonKeyCrypto(commonKey)
 }
 
+// NewCommonKeyCryptoWithIdentifier create CommonKeyCrypto with persisted Identifier
+func NewCommonKeyCryptoWithIdentifier(commonKey []byte, identifier string) (*commonkeycrypto.CommonKeyCrypto, error) {
+	return commonkeycrypto.NewCommonKeyCryptoWithIdentifier(commonKey, identifier)
+}
+
+// NewCommonKeyCryptoWithMode create CommonKeyCrypto with CipherMode
+func NewCommonKeyCryptoWithMode(commonKey []byte, mode commonkeycrypto.CipherMode) (*commonkeycrypto.CommonKeyCrypto, error) {
+	return commonkeycrypto.NewCommonKeyCryptoWithMode(commonKey, mode)
+}
+
+// NewCommonKeyCryptoWithPassphrase create CommonKeyCrypto with passphrase
+func NewCommonKeyCryptoWithPassphrase(passphrase []byte, mode commonkeycrypto.CipherMode, keyDerivation commonkeycrypto.KeyDerivation) (*commonkeycrypto.CommonKeyCrypto, error) {
+	return commonkeycrypto.NewCommonKeyCryptoWithPassphrase(passphrase, mode, keyDerivation)
+}
+
+// NewDeterministicCommonKeyCrypto create CommonKeyCrypto with deterministic AES-SIV mode
+func NewDeterministicCommonKeyCrypto(commonKey []byte) (*commonkeycrypto.CommonKeyCrypto, error) {
+	return commonkeycrypto.NewDeterministicCommonKeyCrypto(commonKey)
+}
+
+// NewKeyring create Keyring
+func NewKeyring() *commonkeycrypto.Keyring {
+	return commonkeycrypto.NewKeyring()
+}
+
+// NewCommonKeyCryptoWithKeyring create CommonKeyCrypto with Keyring
+func NewCommonKeyCryptoWithKeyring(keyring *commonkeycrypto.Keyring) (*commonkeycrypto.CommonKeyCrypto, error) {
+	return commonkeycrypto.NewCommonKeyCryptoWithKeyring(keyring)
+}
+
 // NewPublicKeyCrypto create PublicKeyCrypto
-func NewPublicKeyCrypto(bits int, encryptType publickeycrypto.EncryptKeyType) (*publickeycrypto.PublicKeyCrypto, error) {
+func NewPublicKeyCrypto(bits int, encryptType publickeycrypto.EncryptKeyType, opts ...publickeycrypto.Option) (*publickeycrypto.PublicKeyCrypto, error) {
 	if bits == 0 {
 		switch encryptType {
 		case EncryptTypeRSA:
@@ -32,15 +132,120 @@ func NewPublicKeyCrypto(bits int, encryptType publickeycrypto.EncryptKeyType) (*
 			bits = defaultECDSABits
 		}
 	}
-	return publickeycrypto.NewPublicKeyCrypto(bits, encryptType)
+	return publickeycrypto.NewPublicKeyCrypto(bits, encryptType, opts...)
 }
 
 // NewPublicKeyCryptoWithPEMPublicKey create PublicKeyCrypto with PEM PublicKey
-func NewPublicKeyCryptoWithPEMPublicKey(publickey []byte, encryptType publickeycrypto.EncryptKeyType) (*publickeycrypto.PublicKeyCrypto, error) {
-	return publickeycrypto.NewPublicKeyCryptoWithPEMPublicKey(publickey, encryptType)
+func NewPublicKeyCryptoWithPEMPublicKey(publickey []byte, encryptType publickeycrypto.EncryptKeyType, opts ...publickeycrypto.Option) (*publickeycrypto.PublicKeyCrypto, error) {
+	return publickeycrypto.NewPublicKeyCryptoWithPEMPublicKey(publickey, encryptType, opts...)
+}
+
+// NewPublicKeyCryptoWithPEMPrivateKey create PublicKeyCrypto with PEM PrivateKey
+func NewPublicKeyCryptoWithPEMPrivateKey(privatekey []byte, opts ...publickeycrypto.Option) (*publickeycrypto.PublicKeyCrypto, error) {
+	return publickeycrypto.NewPublicKeyCryptoWithPEMPrivateKey(privatekey, opts...)
+}
+
+// NewPublicKeyCryptoWithEncryptedPEMPrivateKey create PublicKeyCrypto with PEM PrivateKey encrypted with passphrase
+func NewPublicKeyCryptoWithEncryptedPEMPrivateKey(privatekey, passphrase []byte, opts ...publickeycrypto.Option) (*publickeycrypto.PublicKeyCrypto, error) {
+	return publickeycrypto.NewPublicKeyCryptoWithEncryptedPEMPrivateKey(privatekey, passphrase, opts...)
+}
+
+// NewPublicKeyCryptoWithAuthorizedKey create PublicKeyCrypto with OpenSSH authorized_keys line
+func NewPublicKeyCryptoWithAuthorizedKey(authorizedkey []byte, opts ...publickeycrypto.Option) (*publickeycrypto.PublicKeyCrypto, error) {
+	return publickeycrypto.NewPublicKeyCryptoWithAuthorizedKey(authorizedkey, opts...)
+}
+
+// LoadAuthorizedKeys create PublicKeyCrypto for every key in OpenSSH authorized_keys file
+func LoadAuthorizedKeys(authorizedkeys []byte, opts ...publickeycrypto.Option) ([]*publickeycrypto.PublicKeyCrypto, error) {
+	return publickeycrypto.LoadAuthorizedKeys(authorizedkeys, opts...)
+}
+
+// LoadKey create PublicKeyCrypto with key of PEM, DER, JWK, JWKS, OpenSSH or authorized_keys format detected from the data
+func LoadKey(key []byte, opts ...publickeycrypto.Option) (*publickeycrypto.PublicKeyCrypto, error) {
+	return publickeycrypto.LoadKey(key, opts...)
+}
+
+// ConvertPublicKey converts publickey of any format detected by LoadKey to format
+func ConvertPublicKey(key []byte, format publickeycrypto.KeyFormat, opts ...publickeycrypto.Option) ([]byte, error) {
+	return publickeycrypto.ConvertPublicKey(key, format, opts...)
+}
+
+// ConvertPrivateKey converts privatekey of any format detected by LoadKey to format
+func ConvertPrivateKey(key []byte, format publickeycrypto.KeyFormat, opts ...publickeycrypto.Option) ([]byte, error) {
+	return publickeycrypto.ConvertPrivateKey(key, format, opts...)
+}
+
+// NewPublicKeyCryptoWithJWK create PublicKeyCrypto with JWK public or private key
+func NewPublicKeyCryptoWithJWK(jwk []byte, opts ...publickeycrypto.Option) (*publickeycrypto.PublicKeyCrypto, error) {
+	return publickeycrypto.NewPublicKeyCryptoWithJWK(jwk, opts...)
 }
 
 // NewPublicKeyCryptoWithJWKPublicKey create PublicKeyCrypto with JWK PublicKey
-func NewPublicKeyCryptoWithJWKPublicKey(publickey []byte, encryptType publickeycrypto.EncryptKeyType) (*publickeycrypto.PublicKeyCrypto, error) {
-	return publickeycrypto.NewPublicKeyCryptoWithJWKPublicKey(publickey, encryptType)
+func NewPublicKeyCryptoWithJWKPublicKey(publickey []byte, encryptType publickeycrypto.EncryptKeyType, opts ...publickeycrypto.Option) (*publickeycrypto.PublicKeyCrypto, error) {
+	return publickeycrypto.NewPublicKeyCryptoWithJWKPublicKey(publickey, encryptType, opts...)
+}
+
+// WithRsaOAEP create PublicKeyCrypto Option with RSA-OAEP padding
+func WithRsaOAEP(hash crypto.Hash, label []byte) publickeycrypto.Option {
+	return publickeycrypto.WithRsaOAEP(hash, label)
+}
+
+// WithRsaHybrid create PublicKeyCrypto Option with hybrid RSA encryption for data of any length
+func WithRsaHybrid() publickeycrypto.Option {
+	return publickeycrypto.WithRsaHybrid()
+}
+
+// WithEcies create PublicKeyCrypto Option with SEC 1 / ISO 18033-2 ECIES for ECDSA keys
+func WithEcies(kdf publickeycrypto.EciesKDF, eciesCipher publickeycrypto.EciesCipher) publickeycrypto.Option {
+	return publickeycrypto.WithEcies(kdf, eciesCipher)
+}
+
+// WithEd25519Legacy create PublicKeyCrypto Option enabling DecryptLegacy of the legacy ED25519 format
+func WithEd25519Legacy() publickeycrypto.Option {
+	return publickeycrypto.WithEd25519Legacy()
+}
+
+// WithRsaPKCS1v15 create PublicKeyCrypto Option with legacy PKCS #1 v1.5 padding
+func WithRsaPKCS1v15() publickeycrypto.Option {
+	return publickeycrypto.WithRsaPKCS1v15()
+}
+
+// WithSignatureHash create PublicKeyCrypto Option with signature hash
+func WithSignatureHash(hash crypto.Hash) publickeycrypto.Option {
+	return publickeycrypto.WithSignatureHash(hash)
+}
+
+// WithRsaSignatureScheme create PublicKeyCrypto Option with RSA signature scheme
+func WithRsaSignatureScheme(scheme publickeycrypto.RsaSignatureScheme) publickeycrypto.Option {
+	return publickeycrypto.WithRsaSignatureScheme(scheme)
+}
+
+// WithEcdsaSignatureFormat create PublicKeyCrypto Option with ECDSA signature format
+func WithEcdsaSignatureFormat(format publickeycrypto.EcdsaSignatureFormat) publickeycrypto.Option {
+	return publickeycrypto.WithEcdsaSignatureFormat(format)
+}
+
+// WithKeyIDStrategy create PublicKeyCrypto Option with key ID strategy
+func WithKeyIDStrategy(strategy publickeycrypto.KeyIDStrategy) publickeycrypto.Option {
+	return publickeycrypto.WithKeyIDStrategy(strategy)
+}
+
+// WithKeyID create PublicKeyCrypto Option with key ID
+func WithKeyID(kid string) publickeycrypto.Option {
+	return publickeycrypto.WithKeyID(kid)
+}
+
+// WithJWKSKeyID create PublicKeyCrypto Option with kid of the key selected from JWKS
+func WithJWKSKeyID(kid string) publickeycrypto.Option {
+	return publickeycrypto.WithJWKSKeyID(kid)
+}
+
+// WithPassphrase create PublicKeyCrypto Option with passphrase of encrypted private key
+func WithPassphrase(passphrase []byte) publickeycrypto.Option {
+	return publickeycrypto.WithPassphrase(passphrase)
+}
+
+// WithStrictPEM create PublicKeyCrypto Option with standard PEM labels
+func WithStrictPEM() publickeycrypto.Option {
+	return publickeycrypto.WithStrictPEM()
 }
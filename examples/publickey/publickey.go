@@ -4,6 +4,7 @@ import (
 	"log"
 
 	"github.com/howood/cryptotools"
+	"github.com/howood/cryptotools/pkg/publickeycrypto"
 )
 
 func main() {
@@ -35,6 +36,27 @@ func main() {
 	}
 	log.Printf("decryptdata : %s", decryptdataRsa)
 
+	// RSA encryption uses OAEP with SHA-256 by default.
+	// Data encrypted with PKCS #1 v1.5 padding by older versions is decrypted with WithRsaPKCS1v15.
+	privatekeyRsa, err := pcRsa.GetPrivateKey()
+	if err != nil {
+		log.Fatalf("failed  %#v", err)
+	}
+	pcLegacyRsa, err := cryptotools.NewPublicKeyCryptoWithPEMPrivateKey(privatekeyRsa, publickeycrypto.WithRsaPKCS1v15())
+	if err != nil {
+		log.Fatalf("failed %#v", err)
+	}
+	legacydataRsa, err := pcLegacyRsa.Encrypt(testdata)
+	if err != nil {
+		log.Fatalf("failed %#v", err)
+	}
+	log.Printf("legacy encryptdata : %s", legacydataRsa)
+	decryptlegacyRsa, err := pcLegacyRsa.Decrypt(legacydataRsa)
+	if err != nil {
+		log.Fatalf("failed %#v", err)
+	}
+	log.Printf("legacy decryptdata : %s", decryptlegacyRsa)
+
 	pcEcdsa, err := cryptotools.NewPublicKeyCrypto(0, cryptotools.EncryptTypeECDSA)
 	if err != nil {
 		log.Fatalf("failed  :%#v", err)
@@ -3,14 +3,14 @@ module github.com/howood/cryptotools
 go 1.20
 
 require (
-	github.com/ScaleFT/sshkeys v1.2.0
+	github.com/dchest/bcrypt_pbkdf v0.0.0-20150205184540-83f37f9c154a
 	github.com/segmentio/ksuid v1.0.4
 	golang.org/x/crypto v0.11.0
 	gopkg.in/square/go-jose.v2 v2.6.0
 )
 
 require (
-	github.com/dchest/bcrypt_pbkdf v0.0.0-20150205184540-83f37f9c154a // indirect
 	github.com/google/go-cmp v0.5.9 // indirect
+	github.com/stretchr/testify v1.5.1 // indirect
 	golang.org/x/sys v0.10.0 // indirect
 )
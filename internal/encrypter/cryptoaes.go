@@ -4,6 +4,7 @@ import (
 	"crypto/aes"
 	"crypto/cipher"
 	"encoding/base64"
+	"errors"
 	"fmt"
 )
 
@@ -15,13 +16,16 @@ type CryptoAes struct {
 
 // NewCryptoAes create CryptoAes struct
 func NewCryptoAes(encryptionkey []byte, commoniv []byte) (*CryptoAes, error) {
+	if len(commoniv) < aes.BlockSize {
+		return nil, errors.New("Error: common IV must be at least 16 bytes")
+	}
 	c, err := aes.NewCipher(encryptionkey)
 	if err != nil {
 		return nil, fmt.Errorf("Error: NewCipher(%d bytes) = %s", len(encryptionkey), err)
 	}
 	cryptoaes := &CryptoAes{
 		cipherBlock: c,
-		commonIV:    commoniv[:aes.BlockSize],
+		commonIV:    append([]byte{}, commoniv[:aes.BlockSize]...),
 	}
 	return cryptoaes, nil
 }
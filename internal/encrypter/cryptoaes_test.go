@@ -6,7 +6,7 @@ import (
 
 func Test_CryptoAes(t *testing.T) {
 	key := []byte("passw0rdpassw0rdpassw0rdpassw0rd")
-	identifier := "aaaaaaaaaa"
+	identifier := "aaaaaaaaaaaaaaaaaaaa"
 	testdata := `
 {
     "message": "ok",
@@ -38,5 +38,11 @@ func Test_CryptoAes(t *testing.T) {
 		t.Logf("failed test %#v", err)
 	}
 
+	if _, err := NewCryptoAes(key, []byte("aaaaaaaaaa")); err == nil {
+		t.Fatal("failed NewCryptoAes with short identifier")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+
 	t.Log("success CryptoAes")
 }
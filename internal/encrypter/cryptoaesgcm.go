@@ -0,0 +1,108 @@
+package encrypter
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"fmt"
+	"io"
+)
+
+// CryptoAesGcm represents AES-GCM encryption struct
+type CryptoAesGcm struct {
+	key  []byte
+	aead cipher.AEAD
+}
+
+// NewCryptoAesGcm create CryptoAesGcm struct
+func NewCryptoAesGcm(encryptionkey []byte) (*CryptoAesGcm, error) {
+	c, err := aes.NewCipher(encryptionkey)
+	if err != nil {
+		return nil, fmt.Errorf("Error: NewCipher(%d bytes) = %s", len(encryptionkey), err)
+	}
+	aead, err := cipher.NewGCM(c)
+	if err != nil {
+		return nil, err
+	}
+	return &CryptoAesGcm{
+		key:  append([]byte{}, encryptionkey...),
+		aead: aead,
+	}, nil
+}
+
+// Encrypt encrypts a input data with a random nonce prepended to the output
+func (ca *CryptoAesGcm) Encrypt(input []byte) ([]byte, error) {
+	return ca.EncryptWithAAD(input, nil)
+}
+
+// EncryptWithAAD encrypts a input data and authenticates additionalData with it
+func (ca *CryptoAesGcm) EncryptWithAAD(input, additionalData []byte) ([]byte, error) {
+	return sealWithRandomNonce(ca.aead, input, additionalData)
+}
+
+// Decrypt decrypts a input data and verifies its authentication tag
+func (ca *CryptoAesGcm) Decrypt(input []byte) ([]byte, error) {
+	return ca.DecryptWithAAD(input, nil)
+}
+
+// DecryptWithAAD decrypts a input data and verifies that it was encrypted with additionalData
+func (ca *CryptoAesGcm) DecryptWithAAD(input, additionalData []byte) ([]byte, error) {
+	return openWithPrefixedNonce(ca.aead, input, additionalData)
+}
+
+// NonceSize returns the size of the nonce prepended to the output
+func (ca *CryptoAesGcm) NonceSize() int {
+	return ca.aead.NonceSize()
+}
+
+// NewStreamWriter create StreamWriter struct encrypting to w with a key derived from salt
+func (ca *CryptoAesGcm) NewStreamWriter(salt, additionalData []byte, w io.Writer) (*StreamWriter, error) {
+	aead, noncePrefix, err := ca.streamAead(salt)
+	if err != nil {
+		return nil, err
+	}
+	return NewStreamWriter(aead, noncePrefix, additionalData, w)
+}
+
+// NewStreamReader create StreamReader struct decrypting from r with a key derived from salt
+func (ca *CryptoAesGcm) NewStreamReader(salt, additionalData []byte, r io.Reader) (*StreamReader, error) {
+	aead, noncePrefix, err := ca.streamAead(salt)
+	if err != nil {
+		return nil, err
+	}
+	return NewStreamReader(aead, noncePrefix, additionalData, r)
+}
+
+func (ca *CryptoAesGcm) streamAead(salt []byte) (cipher.AEAD, []byte, error) {
+	key, noncePrefix, err := deriveStreamKey(ca.key, salt, StreamNoncePrefixSize(ca.aead))
+	if err != nil {
+		return nil, nil, err
+	}
+	streamEncrypter, err := NewCryptoAesGcm(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return streamEncrypter.aead, noncePrefix, nil
+}
+
+// EncryptWithBase64 encrypts a input data to base64 string
+func (ca *CryptoAesGcm) EncryptWithBase64(input string) (string, error) {
+	ciphertext, err := ca.Encrypt([]byte(input))
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// DecryptWithBase64 decrypts a input data to base64 string
+func (ca *CryptoAesGcm) DecryptWithBase64(input string) (string, error) {
+	inputdecoded, err := base64.StdEncoding.DecodeString(input)
+	if err != nil {
+		return "", err
+	}
+	decrypttext, err := ca.Decrypt(inputdecoded)
+	if err != nil {
+		return "", err
+	}
+	return string(decrypttext), nil
+}
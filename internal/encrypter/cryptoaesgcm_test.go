@@ -0,0 +1,81 @@
+package encrypter
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func Test_CryptoAesGcm(t *testing.T) {
+	key := []byte("passw0rdpassw0rdpassw0rdpassw0rd")
+	testdata := `
+{
+    "message": "ok",
+    "message2": ["ng", "ng2"]
+}
+`
+	cryptoaesgcm, err := NewCryptoAesGcm(key)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	encryptdata, err := cryptoaesgcm.EncryptWithBase64(testdata)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	decryptdata, err := cryptoaesgcm.DecryptWithBase64(encryptdata)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if decryptdata != testdata {
+		t.Fatal("failed CryptoAesGcm ")
+	}
+
+	encryptdata2, err := cryptoaesgcm.EncryptWithBase64(testdata)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if encryptdata == encryptdata2 {
+		t.Fatal("failed CryptoAesGcm nonce is reused")
+	}
+
+	tampered, _ := base64.StdEncoding.DecodeString(encryptdata)
+	tampered[len(tampered)-1] ^= 0x01
+	if _, err := cryptoaesgcm.Decrypt(tampered); !errors.Is(err, ErrAuthenticationFailed) {
+		t.Fatalf("failed Decrypt tampered data %#v", err)
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+
+	aad := []byte("row-1")
+	encryptdataaad, err := cryptoaesgcm.EncryptWithAAD([]byte(testdata), aad)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if decryptdata, err := cryptoaesgcm.DecryptWithAAD(encryptdataaad, aad); err != nil || string(decryptdata) != testdata {
+		t.Fatalf("failed DecryptWithAAD %#v", err)
+	}
+	if _, err := cryptoaesgcm.DecryptWithAAD(encryptdataaad, []byte("row-2")); !errors.Is(err, ErrAuthenticationFailed) {
+		t.Fatalf("failed DecryptWithAAD with other additional data %#v", err)
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+
+	if _, err := cryptoaesgcm.DecryptWithBase64("aaaaaaa"); err == nil {
+		t.Fatal("failed DecryptWithBase64 ")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	if _, err := cryptoaesgcm.Decrypt([]byte("short")); err == nil {
+		t.Fatal("failed Decrypt ")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+
+	if _, err := NewCryptoAesGcm([]byte("sssss")); err == nil {
+		t.Fatal("failed NewCryptoAesGcm ")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+
+	t.Log("success CryptoAesGcm")
+}
@@ -0,0 +1,199 @@
+package encrypter
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// AesSivTagSize is the size of the synthetic IV prepended to AES-SIV output
+const AesSivTagSize = aes.BlockSize
+
+// CryptoAesSiv represents deterministic AES-SIV (RFC 5297) encryption struct.
+// The same input and associated data always give the same output, and the output is authenticated.
+type CryptoAesSiv struct {
+	macBlock cipher.Block
+	ctrBlock cipher.Block
+}
+
+// NewCryptoAesSiv create CryptoAesSiv struct.
+// encryptionkey must be 32, 48 or 64 bytes, which is split into the S2V key and the CTR key.
+func NewCryptoAesSiv(encryptionkey []byte) (*CryptoAesSiv, error) {
+	switch len(encryptionkey) {
+	case 32, 48, 64:
+	default:
+		return nil, fmt.Errorf("Error: NewCryptoAesSiv(%d bytes) = invalid key size", len(encryptionkey))
+	}
+	half := len(encryptionkey) / 2
+	macBlock, err := aes.NewCipher(encryptionkey[:half])
+	if err != nil {
+		return nil, err
+	}
+	ctrBlock, err := aes.NewCipher(encryptionkey[half:])
+	if err != nil {
+		return nil, err
+	}
+	return &CryptoAesSiv{
+		macBlock: macBlock,
+		ctrBlock: ctrBlock,
+	}, nil
+}
+
+// Encrypt encrypts a input data with the synthetic IV prepended to the output
+func (cs *CryptoAesSiv) Encrypt(input []byte) ([]byte, error) {
+	return cs.Seal(input), nil
+}
+
+// EncryptWithAAD encrypts a input data and authenticates additionalData with it.
+// Empty additionalData gives the same output as Encrypt.
+func (cs *CryptoAesSiv) EncryptWithAAD(input, additionalData []byte) ([]byte, error) {
+	return cs.Seal(input, aesSivAdditionalData(additionalData)...), nil
+}
+
+// Decrypt decrypts a input data and verifies its synthetic IV
+func (cs *CryptoAesSiv) Decrypt(input []byte) ([]byte, error) {
+	return cs.Open(input)
+}
+
+// DecryptWithAAD decrypts a input data and verifies that it was encrypted with additionalData
+func (cs *CryptoAesSiv) DecryptWithAAD(input, additionalData []byte) ([]byte, error) {
+	return cs.Open(input, aesSivAdditionalData(additionalData)...)
+}
+
+// NonceSize returns 0 as AES-SIV does not use a random nonce
+func (cs *CryptoAesSiv) NonceSize() int {
+	return 0
+}
+
+// Seal encrypts plaintext with associated data components as in RFC 5297
+func (cs *CryptoAesSiv) Seal(plaintext []byte, additionalData ...[]byte) []byte {
+	v := cs.s2v(plaintext, additionalData)
+	out := make([]byte, AesSivTagSize+len(plaintext))
+	copy(out, v)
+	cs.ctr(out[AesSivTagSize:], plaintext, v)
+	return out
+}
+
+// Open decrypts ciphertext with associated data components as in RFC 5297
+func (cs *CryptoAesSiv) Open(ciphertext []byte, additionalData ...[]byte) ([]byte, error) {
+	if len(ciphertext) < AesSivTagSize {
+		return nil, errors.New("Invalid inputdata")
+	}
+	v := ciphertext[:AesSivTagSize]
+	plaintext := make([]byte, len(ciphertext)-AesSivTagSize)
+	cs.ctr(plaintext, ciphertext[AesSivTagSize:], v)
+	if subtle.ConstantTimeCompare(cs.s2v(plaintext, additionalData), v) != 1 {
+		return nil, ErrAuthenticationFailed
+	}
+	return plaintext, nil
+}
+
+// EncryptWithBase64 encrypts a input data to base64 string
+func (cs *CryptoAesSiv) EncryptWithBase64(input string) (string, error) {
+	ciphertext, err := cs.Encrypt([]byte(input))
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// DecryptWithBase64 decrypts a input data to base64 string
+func (cs *CryptoAesSiv) DecryptWithBase64(input string) (string, error) {
+	inputdecoded, err := base64.StdEncoding.DecodeString(input)
+	if err != nil {
+		return "", err
+	}
+	decrypttext, err := cs.Decrypt(inputdecoded)
+	if err != nil {
+		return "", err
+	}
+	return string(decrypttext), nil
+}
+
+// aesSivAdditionalData converts additionalData to S2V components.
+// nil and empty additionalData are no component, so that they are the same as no associated data.
+func aesSivAdditionalData(additionalData []byte) [][]byte {
+	if len(additionalData) == 0 {
+		return nil
+	}
+	return [][]byte{additionalData}
+}
+
+// s2v computes the synthetic IV over associated data components and the plaintext
+func (cs *CryptoAesSiv) s2v(plaintext []byte, additionalData [][]byte) []byte {
+	d := cs.cmac(make([]byte, aes.BlockSize))
+	for _, ad := range additionalData {
+		dbl(d)
+		xorBytes(d, cs.cmac(ad))
+	}
+	var t []byte
+	if len(plaintext) >= aes.BlockSize {
+		t = append([]byte{}, plaintext...)
+		xorBytes(t[len(t)-aes.BlockSize:], d)
+	} else {
+		dbl(d)
+		t = make([]byte, aes.BlockSize)
+		copy(t, plaintext)
+		t[len(plaintext)] = 0x80
+		xorBytes(t, d)
+	}
+	return cs.cmac(t)
+}
+
+// cmac computes AES-CMAC (RFC 4493) with the S2V key
+func (cs *CryptoAesSiv) cmac(input []byte) []byte {
+	k1 := make([]byte, aes.BlockSize)
+	cs.macBlock.Encrypt(k1, k1)
+	dbl(k1)
+	n := (len(input) + aes.BlockSize - 1) / aes.BlockSize
+	last := make([]byte, aes.BlockSize)
+	if n > 0 && len(input)%aes.BlockSize == 0 {
+		copy(last, input[(n-1)*aes.BlockSize:])
+		xorBytes(last, k1)
+	} else {
+		if n == 0 {
+			n = 1
+		}
+		k2 := append([]byte{}, k1...)
+		dbl(k2)
+		rest := input[(n-1)*aes.BlockSize:]
+		copy(last, rest)
+		last[len(rest)] = 0x80
+		xorBytes(last, k2)
+	}
+	x := make([]byte, aes.BlockSize)
+	for i := 0; i < n-1; i++ {
+		xorBytes(x, input[i*aes.BlockSize:(i+1)*aes.BlockSize])
+		cs.macBlock.Encrypt(x, x)
+	}
+	xorBytes(x, last)
+	cs.macBlock.Encrypt(x, x)
+	return x
+}
+
+// ctr encrypts src to dst in CTR mode with the synthetic IV as counter
+func (cs *CryptoAesSiv) ctr(dst, src, v []byte) {
+	q := append([]byte{}, v...)
+	q[8] &= 0x7f
+	q[12] &= 0x7f
+	cipher.NewCTR(cs.ctrBlock, q).XORKeyStream(dst, src)
+}
+
+// dbl multiplies a block by x in GF(2^128)
+func dbl(b []byte) {
+	carry := b[0] >> 7
+	for i := 0; i < len(b)-1; i++ {
+		b[i] = b[i]<<1 | b[i+1]>>7
+	}
+	b[len(b)-1] = b[len(b)-1]<<1 ^ 0x87*carry
+}
+
+// xorBytes xors src into dst
+func xorBytes(dst, src []byte) {
+	for i := range src {
+		dst[i] ^= src[i]
+	}
+}
@@ -0,0 +1,120 @@
+package encrypter
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func Test_CryptoAesSiv(t *testing.T) {
+	// RFC 5297 Appendix A test vectors
+	testvectors := []struct {
+		key            string
+		additionalData []string
+		plaintext      string
+		ciphertext     string
+	}{
+		{
+			key:            "fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff",
+			additionalData: []string{"101112131415161718191a1b1c1d1e1f2021222324252627"},
+			plaintext:      "112233445566778899aabbccddee",
+			ciphertext:     "85632d07c6e8f37f950acd320a2ecc9340c02b9690c4dc04daef7f6afe5c",
+		},
+		{
+			key: "7f7e7d7c7b7a79787776757473727170404142434445464748494a4b4c4d4e4f",
+			additionalData: []string{
+				"00112233445566778899aabbccddeeffdeaddadadeaddadaffeeddccbbaa99887766554433221100",
+				"102030405060708090a0",
+				"09f911029d74e35bd84156c5635688c0",
+			},
+			plaintext:  "7468697320697320736f6d6520706c61696e7465787420746f20656e6372797074207573696e67205349562d414553",
+			ciphertext: "7bdb6e3b432667eb06f4d14bff2fbd0fcb900f2fddbe404326601965c889bf17dba77ceb094fa663b7a3f748ba8af829ea64ad544a272e9c485b62a3fd5c0d",
+		},
+	}
+	for _, tv := range testvectors {
+		key, _ := hex.DecodeString(tv.key)
+		plaintext, _ := hex.DecodeString(tv.plaintext)
+		ciphertext, _ := hex.DecodeString(tv.ciphertext)
+		var additionalData [][]byte
+		for _, ad := range tv.additionalData {
+			b, _ := hex.DecodeString(ad)
+			additionalData = append(additionalData, b)
+		}
+		cryptoaessiv, err := NewCryptoAesSiv(key)
+		if err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+		if sealed := cryptoaessiv.Seal(plaintext, additionalData...); !bytes.Equal(sealed, ciphertext) {
+			t.Fatalf("failed CryptoAesSiv Seal %x", sealed)
+		}
+		opened, err := cryptoaessiv.Open(ciphertext, additionalData...)
+		if err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+		if !bytes.Equal(opened, plaintext) {
+			t.Fatalf("failed CryptoAesSiv Open %x", opened)
+		}
+	}
+
+	cryptoaessiv, err := NewCryptoAesSiv([]byte("passw0rdpassw0rdpassw0rdpassw0rdpassw0rdpassw0rdpassw0rdpassw0rd"))
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	for _, testdata := range []string{"", "short", "exactly16bytes!!", "test@example.com is longer than a block"} {
+		encryptdata, err := cryptoaessiv.EncryptWithBase64(testdata)
+		if err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+		encryptdata2, _ := cryptoaessiv.EncryptWithBase64(testdata)
+		if encryptdata != encryptdata2 {
+			t.Fatal("failed CryptoAesSiv is not deterministic")
+		}
+		decryptdata, err := cryptoaessiv.DecryptWithBase64(encryptdata)
+		if err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+		if decryptdata != testdata {
+			t.Fatal("failed CryptoAesSiv ")
+		}
+	}
+
+	plaintext := []byte("test@example.com")
+	encrypted, _ := cryptoaessiv.Encrypt(plaintext)
+	for _, additionalData := range [][]byte{nil, {}} {
+		encryptedWithAAD, _ := cryptoaessiv.EncryptWithAAD(plaintext, additionalData)
+		if !bytes.Equal(encrypted, encryptedWithAAD) {
+			t.Fatalf("failed EncryptWithAAD with empty additional data %x", encryptedWithAAD)
+		}
+		if decrypted, err := cryptoaessiv.DecryptWithAAD(encrypted, additionalData); err != nil || !bytes.Equal(decrypted, plaintext) {
+			t.Fatalf("failed DecryptWithAAD Encrypt output %#v", err)
+		}
+		if decrypted, err := cryptoaessiv.Decrypt(encryptedWithAAD); err != nil || !bytes.Equal(decrypted, plaintext) {
+			t.Fatalf("failed Decrypt EncryptWithAAD output %#v", err)
+		}
+	}
+
+	encryptdata, _ := cryptoaessiv.EncryptWithAAD(plaintext, []byte("users.email"))
+	if _, err := cryptoaessiv.DecryptWithAAD(encryptdata, []byte("orders.email")); !errors.Is(err, ErrAuthenticationFailed) {
+		t.Fatalf("failed DecryptWithAAD with other additional data %#v", err)
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	encryptdata[len(encryptdata)-1] ^= 0x01
+	if _, err := cryptoaessiv.DecryptWithAAD(encryptdata, []byte("users.email")); !errors.Is(err, ErrAuthenticationFailed) {
+		t.Fatalf("failed Decrypt tampered data %#v", err)
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	if _, err := cryptoaessiv.Decrypt([]byte("short")); err == nil {
+		t.Fatal("failed Decrypt ")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	if _, err := NewCryptoAesSiv([]byte("passw0rdpassw0rd")); err == nil {
+		t.Fatal("failed NewCryptoAesSiv ")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	t.Log("success CryptoAesSiv")
+}
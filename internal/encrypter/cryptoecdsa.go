@@ -14,20 +14,51 @@ import (
 	"github.com/howood/cryptotools/internal/entity"
 )
 
+// errorEcdsaLegacyAAD is returned for additional data with the legacy format,
+// whose HMAC-SHA1(iv || ct) has no length framing to bind additional data
+const errorEcdsaLegacyAAD = "Additional data requires ECIES"
+
 // CryptoEcdsa represents Ecdsa encryption struct
 type CryptoEcdsa struct {
-	ecdsakey *entity.EcdsaKey
+	ecdsakey    *entity.EcdsaKey
+	eciesKDF    entity.EciesKDF
+	eciesCipher entity.EciesCipher
 }
 
-// NewCryptoEcdsa create CryptoEcdsa struct
+// NewCryptoEcdsa create CryptoEcdsa struct with the legacy format of AES-128-CBC and HMAC-SHA1
 func NewCryptoEcdsa(ecdsakey *entity.EcdsaKey) *CryptoEcdsa {
 	return &CryptoEcdsa{
 		ecdsakey: ecdsakey,
 	}
 }
 
+// NewCryptoEcdsaWithECIES create CryptoEcdsa struct with SEC 1 / ISO 18033-2 ECIES.
+// The wire format is described in ecies.go. P-256, P-384 and P-521 keys are supported.
+func NewCryptoEcdsaWithECIES(ecdsakey *entity.EcdsaKey, kdf entity.EciesKDF, eciesCipher entity.EciesCipher) (*CryptoEcdsa, error) {
+	if err := validateEcies(kdf, eciesCipher); err != nil {
+		return nil, err
+	}
+	return &CryptoEcdsa{
+		ecdsakey:    ecdsakey,
+		eciesKDF:    kdf,
+		eciesCipher: eciesCipher,
+	}, nil
+}
+
 // Encrypt encrypts a input data
 func (ce *CryptoEcdsa) Encrypt(input []byte) ([]byte, error) {
+	return ce.EncryptWithAAD(input, nil)
+}
+
+// EncryptWithAAD encrypts a input data and authenticates additionalData with its MAC.
+// Additional data is supported only with ECIES
+func (ce *CryptoEcdsa) EncryptWithAAD(input, additionalData []byte) ([]byte, error) {
+	if ce.eciesKDF != "" {
+		return ce.encryptECIES(input, additionalData)
+	}
+	if len(additionalData) > 0 {
+		return nil, errors.New(errorEcdsaLegacyAAD)
+	}
 	ephemeral, err := ecdsa.GenerateKey(ce.ecdsakey.PublicKey.Curve, rand.Reader)
 	if err != nil {
 		return nil, err
@@ -64,10 +95,25 @@ func (ce *CryptoEcdsa) Encrypt(input []byte) ([]byte, error) {
 
 // Decrypt decrypts a input data
 func (ce *CryptoEcdsa) Decrypt(input []byte) ([]byte, error) {
+	return ce.DecryptWithAAD(input, nil)
+}
+
+// DecryptWithAAD decrypts a input data and verifies that it was encrypted with additionalData.
+// Additional data is supported only with ECIES
+func (ce *CryptoEcdsa) DecryptWithAAD(input, additionalData []byte) ([]byte, error) {
+	if ce.eciesKDF != "" {
+		return ce.decryptECIES(input, additionalData)
+	}
+	if len(additionalData) > 0 {
+		return nil, errors.New(errorEcdsaLegacyAAD)
+	}
+	if len(input) < 1 || len(input) < 1+int(input[0]) {
+		return nil, errors.New("Invalid inputdata")
+	}
 	ephLen := int(input[0])
 	ephPub := input[1 : 1+ephLen]
 	ct := input[1+ephLen:]
-	if len(ct) < (sha1.Size + aes.BlockSize) {
+	if len(ct) < (sha1.Size+aes.BlockSize) || (len(ct)-sha1.Size)%aes.BlockSize != 0 {
 		return nil, errors.New("Invalid inputdata")
 	}
 
@@ -48,6 +48,36 @@ func Test_CryptoEcdsa(t *testing.T) {
 		t.Fatal("failed CryptoRsa ")
 	}
 
+	if _, err := cryptoecdsa.EncryptWithAAD([]byte(testdata), []byte("tenant-1")); err == nil {
+		t.Fatal("failed EncryptWithAAD with the legacy format")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	encryptdataraw, err := cryptoecdsa.Encrypt([]byte(testdata))
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if _, err := cryptoecdsa.DecryptWithAAD(encryptdataraw, []byte("tenant-1")); err == nil {
+		t.Fatal("failed DecryptWithAAD with the legacy format")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+
+	for _, invalid := range [][]byte{nil, {0xff}, {0x01, 0x04}} {
+		if _, err := cryptoecdsa.Decrypt(invalid); err == nil {
+			t.Fatal("failed Decrypt invalid data")
+		} else {
+			t.Logf("failed test %#v", err)
+		}
+	}
+	padded := addPaddingBlock([]byte("data"))
+	padded[len(padded)-2] = 0x01
+	if _, err := removePaddingBlock(padded); err == nil {
+		t.Fatal("failed removePaddingBlock ")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+
 	if _, err := cryptoecdsa.DecryptWithBase64("sssssss"); err == nil {
 		t.Fatal("failed DecryptWithBase64 ")
 	} else {
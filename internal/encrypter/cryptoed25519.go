@@ -1,22 +1,36 @@
 package encrypter
 
 import (
-	"crypto/rand"
+	"crypto/cipher"
+	"crypto/sha256"
 	"crypto/sha512"
 	"encoding/base64"
 	"errors"
-	"unsafe"
+	"io"
 
 	"github.com/howood/cryptotools/internal/encrypter/edwards25519"
 	"github.com/howood/cryptotools/internal/entity"
+	"golang.org/x/crypto/chacha20poly1305"
 	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
 )
 
-// CryptoEd25519 represents ED25519 encryption struct
+// CryptoEd25519 represents ED25519 encryption struct.
+// It encrypts with ECIES over X25519: an ephemeral X25519 key, an HKDF-SHA256 derived key and ChaCha20-Poly1305.
+// The output is the ephemeral public key (32 bytes) || ChaCha20-Poly1305 ciphertext.
 type CryptoEd25519 struct {
 	ed25519key *entity.Ed25519Key
+	legacy     bool
 }
 
+const (
+	x25519KeySize       = 32
+	ed25519LegacyMaxLen = 64
+)
+
+// ed25519HkdfInfo is HKDF info binding derived keys to this scheme
+var ed25519HkdfInfo = []byte("cryptotools X25519-HKDF-SHA256-ChaCha20Poly1305")
+
 // NewCryptoEd25519 create CryptoEd25519 struct
 func NewCryptoEd25519(ed25519key *entity.Ed25519Key) *CryptoEd25519 {
 	return &CryptoEd25519{
@@ -24,42 +38,108 @@ func NewCryptoEd25519(ed25519key *entity.Ed25519Key) *CryptoEd25519 {
 	}
 }
 
+// NewCryptoEd25519WithLegacy create CryptoEd25519 struct whose DecryptLegacy decrypts the legacy format.
+// Decrypt never falls back to the legacy format, as it cannot be told apart from tampered data.
+func NewCryptoEd25519WithLegacy(ed25519key *entity.Ed25519Key) *CryptoEd25519 {
+	return &CryptoEd25519{
+		ed25519key: ed25519key,
+		legacy:     true,
+	}
+}
+
 // Encrypt encrypts a input data
 func (ce *CryptoEd25519) Encrypt(input []byte) ([]byte, error) {
+	return ce.EncryptWithAAD(input, nil)
+}
+
+// EncryptWithAAD encrypts a input data and authenticates additionalData with it
+func (ce *CryptoEd25519) EncryptWithAAD(input, additionalData []byte) ([]byte, error) {
 	publickey, err := ce.publicKeyToCurve25519()
 	if err != nil {
 		return nil, err
 	}
-	var r, R, S, KB [32]byte
+	return sealX25519(publickey, input, additionalData)
+}
+
+// Decrypt decrypts a input data
+func (ce *CryptoEd25519) Decrypt(input []byte) ([]byte, error) {
+	return ce.DecryptWithAAD(input, nil)
+}
 
-	if _, err := rand.Read(r[:]); err != nil {
+// DecryptWithAAD decrypts a input data and verifies that it was encrypted with additionalData
+func (ce *CryptoEd25519) DecryptWithAAD(input, additionalData []byte) ([]byte, error) {
+	publickey, err := ce.publicKeyToCurve25519()
+	if err != nil {
 		return nil, err
 	}
-	r[0] &= 248
-	r[31] &= 127
-	r[31] |= 64
-
-	copy(KB[:], publickey)
+	return openX25519(ce.privateKeyToCurve25519(), publickey, input, additionalData)
+}
 
-	curve25519.ScalarBaseMult(&R, &r)
-	curve25519.ScalarMult(&S, &r, &KB)
-	kE := sha512.Sum512(S[:])
+// sealX25519 encrypts input to X25519 publickey with an ephemeral key
+func sealX25519(publickey, input, additionalData []byte) ([]byte, error) {
+	ephemeralPrivate, err := makeRandomData(x25519KeySize)
+	if err != nil {
+		return nil, err
+	}
+	ephemeralPublic, err := curve25519.X25519(ephemeralPrivate, curve25519.Basepoint)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := curve25519.X25519(ephemeralPrivate, publickey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newX25519Aead(shared, ephemeralPublic, publickey)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	return aead.Seal(ephemeralPublic, nonce, input, additionalData), nil
+}
 
-	srclen := len(input)
-	if srclen > 64 {
-		return nil, errors.New("source data is exceed 64 bytes")
+// openX25519 decrypts input sealed by sealX25519 with X25519 privatekey
+func openX25519(privatekey, publickey, input, additionalData []byte) ([]byte, error) {
+	if len(input) < x25519KeySize+chacha20poly1305.Overhead {
+		return nil, errors.New("Invalid inputdata")
+	}
+	ephemeralPublic := input[:x25519KeySize]
+	shared, err := curve25519.X25519(privatekey, ephemeralPublic)
+	if err != nil {
+		return nil, err
 	}
-	encryptData := make([]byte, 32+srclen)
-	copy(encryptData[:32], R[:])
-	for i := 0; i < srclen; i++ {
-		encryptData[32+i] = input[i] ^ kE[i]
+	aead, err := newX25519Aead(shared, ephemeralPublic, publickey)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	decryptData, err := aead.Open(nil, nonce, input[x25519KeySize:], additionalData)
+	if err != nil {
+		return nil, ErrAuthenticationFailed
 	}
+	return decryptData, nil
+}
 
-	return encryptData, nil
+// newX25519Aead derives ChaCha20-Poly1305 key from the shared secret and both public keys.
+// A zero nonce is used as the key is unique to each ephemeral key.
+func newX25519Aead(shared, ephemeralPublic, publickey []byte) (cipher.AEAD, error) {
+	salt := append(append([]byte{}, ephemeralPublic...), publickey...)
+	key := make([]byte, chacha20poly1305.KeySize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, shared, salt, ed25519HkdfInfo), key); err != nil {
+		return nil, err
+	}
+	return chacha20poly1305.New(key)
 }
 
-// Decrypt decrypts a input data
-func (ce *CryptoEd25519) Decrypt(input []byte) ([]byte, error) {
+// DecryptLegacy decrypts the legacy format, which is the ephemeral public key || input XOR sha512(shared).
+// The legacy format is unauthenticated, so the output has no integrity check.
+// It is only enabled by NewCryptoEd25519WithLegacy.
+func (ce *CryptoEd25519) DecryptLegacy(input []byte) ([]byte, error) {
+	if !ce.legacy {
+		return nil, errors.New("Legacy format is not enabled")
+	}
+	if len(input) < x25519KeySize || len(input) > x25519KeySize+ed25519LegacyMaxLen {
+		return nil, errors.New("Invalid inputdata")
+	}
 	privatekey := ce.privateKeyToCurve25519()
 
 	var R, S, kB [32]byte
@@ -99,8 +179,7 @@ func (ce *CryptoEd25519) DecryptWithBase64(input string) ([]byte, error) {
 func (ce *CryptoEd25519) privateKeyToCurve25519() []byte {
 	var curve25519Private [32]byte
 	h := sha512.New()
-	privatekey := *(*[]byte)(unsafe.Pointer(ce.ed25519key.PrivateKey))
-	h.Write(privatekey[:32])
+	h.Write(ce.ed25519key.PrivateKey.Seed())
 	digest := h.Sum(nil)
 
 	digest[0] &= 248
@@ -114,8 +193,7 @@ func (ce *CryptoEd25519) privateKeyToCurve25519() []byte {
 func (ce *CryptoEd25519) publicKeyToCurve25519() ([]byte, error) {
 	var curve25519Public, publickeyBytes [32]byte
 	var A edwards25519.ExtendedGroupElement
-	pubkey := *(*[]byte)(unsafe.Pointer(ce.ed25519key.PublicKey))
-	copy(publickeyBytes[:], pubkey)
+	copy(publickeyBytes[:], *ce.ed25519key.PublicKey)
 	if !A.FromBytes(&publickeyBytes) {
 		return nil, errors.New("cannot convert to Curve25519 publickey")
 	}
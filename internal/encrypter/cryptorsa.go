@@ -1,26 +1,89 @@
 package encrypter
 
 import (
+	"crypto"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/base64"
+	"errors"
+	"hash"
+
 	"github.com/howood/cryptotools/internal/entity"
 )
 
+// ErrRsaOAEPDecryption is returned when RSA-OAEP decryption fails.
+// The cause is not distinguished so that it cannot be used as a padding oracle.
+var ErrRsaOAEPDecryption = errors.New("RSA-OAEP decryption failed: data may use PKCS #1 v1.5 padding, other OAEP parameters or another key")
+
 // CryptoRsa represents Rsa encryption struct
 type CryptoRsa struct {
-	rsakey *entity.RsaKey
+	rsakey    *entity.RsaKey
+	oaepHash  func() hash.Hash
+	oaepLabel []byte
+	hybrid    bool
 }
 
-// NewCryptoRsa create CryptoRsa struct
+const (
+	// rsaHybridVersion is the marker byte prepended to hybrid encryption output
+	rsaHybridVersion byte = 0x01
+	// rsaHybridDataKeySize is the size of AES-256-GCM data key wrapped by RSA-OAEP in hybrid encryption
+	rsaHybridDataKeySize = 32
+)
+
+// NewCryptoRsa create CryptoRsa struct with PKCS #1 v1.5 padding
 func NewCryptoRsa(rsakey *entity.RsaKey) *CryptoRsa {
 	return &CryptoRsa{
 		rsakey: rsakey,
 	}
 }
 
+// NewCryptoRsaWithOAEP create CryptoRsa struct with OAEP padding.
+// hashType must be SHA-1, SHA-256, SHA-384 or SHA-512, and is used for both OAEP and MGF1 as WebCrypto does.
+func NewCryptoRsaWithOAEP(rsakey *entity.RsaKey, hashType crypto.Hash, label []byte) (*CryptoRsa, error) {
+	var oaepHash func() hash.Hash
+	switch hashType {
+	case crypto.SHA1:
+		oaepHash = sha1.New
+	case crypto.SHA256:
+		oaepHash = sha256.New
+	case crypto.SHA384:
+		oaepHash = sha512.New384
+	case crypto.SHA512:
+		oaepHash = sha512.New
+	default:
+		return nil, errors.New("Invalid OAEP hash")
+	}
+	return &CryptoRsa{
+		rsakey:    rsakey,
+		oaepHash:  oaepHash,
+		oaepLabel: append([]byte{}, label...),
+	}, nil
+}
+
+// NewCryptoRsaHybrid create CryptoRsa struct with hybrid encryption for data of any length.
+// A random AES-256-GCM data key encrypts the data and is wrapped with RSA-OAEP.
+// The output is version marker(1) || wrapped data key (modulus size) || GCM nonce || GCM ciphertext,
+// and the version marker and the wrapped data key are authenticated as GCM associated data.
+func NewCryptoRsaHybrid(rsakey *entity.RsaKey, hashType crypto.Hash, label []byte) (*CryptoRsa, error) {
+	cryptorsa, err := NewCryptoRsaWithOAEP(rsakey, hashType, label)
+	if err != nil {
+		return nil, err
+	}
+	cryptorsa.hybrid = true
+	return cryptorsa, nil
+}
+
 // Encrypt encrypts a input data
 func (cr *CryptoRsa) Encrypt(input []byte) ([]byte, error) {
+	if cr.hybrid {
+		return cr.encryptHybrid(input)
+	}
+	if cr.oaepHash != nil {
+		return rsa.EncryptOAEP(cr.oaepHash(), rand.Reader, cr.rsakey.PublicKey, input, cr.oaepLabel)
+	}
 	ciphertext, err := rsa.EncryptPKCS1v15(rand.Reader, cr.rsakey.PublicKey, input)
 	if err != nil {
 		return nil, err
@@ -28,8 +91,20 @@ func (cr *CryptoRsa) Encrypt(input []byte) ([]byte, error) {
 	return ciphertext, nil
 }
 
-// Decrypt decrypts a input data
+// Decrypt decrypts a input data.
+// With OAEP padding, input other than the modulus size must start with the hybrid version marker
+// and is decrypted as hybrid encryption. Any other OAEP failure returns ErrRsaOAEPDecryption.
 func (cr *CryptoRsa) Decrypt(input []byte) ([]byte, error) {
+	if cr.oaepHash != nil && len(input) != cr.rsakey.PrivateKey.Size() {
+		return cr.decryptHybrid(input)
+	}
+	if cr.oaepHash != nil {
+		plaintext, err := rsa.DecryptOAEP(cr.oaepHash(), rand.Reader, cr.rsakey.PrivateKey, input, cr.oaepLabel)
+		if err != nil {
+			return nil, ErrRsaOAEPDecryption
+		}
+		return plaintext, nil
+	}
 	return rsa.DecryptPKCS1v15(rand.Reader, cr.rsakey.PrivateKey, input)
 }
 
@@ -50,3 +125,44 @@ func (cr *CryptoRsa) DecryptWithBase64(input string) ([]byte, error) {
 	}
 	return cr.Decrypt(inputdecoded)
 }
+
+func (cr *CryptoRsa) encryptHybrid(input []byte) ([]byte, error) {
+	dataKey, err := makeRandomData(rsaHybridDataKeySize)
+	if err != nil {
+		return nil, err
+	}
+	wrappedKey, err := rsa.EncryptOAEP(cr.oaepHash(), rand.Reader, cr.rsakey.PublicKey, dataKey, cr.oaepLabel)
+	if err != nil {
+		return nil, err
+	}
+	cryptoaesgcm, err := NewCryptoAesGcm(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	header := append([]byte{rsaHybridVersion}, wrappedKey...)
+	ciphertext, err := cryptoaesgcm.EncryptWithAAD(input, header)
+	if err != nil {
+		return nil, err
+	}
+	return append(header, ciphertext...), nil
+}
+
+func (cr *CryptoRsa) decryptHybrid(input []byte) ([]byte, error) {
+	keySize := cr.rsakey.PrivateKey.Size()
+	if len(input) <= 1+keySize || input[0] != rsaHybridVersion {
+		return nil, errors.New("Invalid hybrid ciphertext")
+	}
+	header := input[:1+keySize]
+	dataKey, err := rsa.DecryptOAEP(cr.oaepHash(), rand.Reader, cr.rsakey.PrivateKey, header[1:], cr.oaepLabel)
+	if err != nil {
+		return nil, err
+	}
+	if len(dataKey) != rsaHybridDataKeySize {
+		return nil, errors.New("Invalid hybrid data key")
+	}
+	cryptoaesgcm, err := NewCryptoAesGcm(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return cryptoaesgcm.DecryptWithAAD(input[1+keySize:], header)
+}
@@ -1,6 +1,10 @@
 package encrypter
 
 import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"errors"
 	"reflect"
 	"testing"
 
@@ -120,3 +124,120 @@ func Test_CryptoRsa(t *testing.T) {
 	}
 	t.Log("success CryptoRsa")
 }
+
+func Test_CryptoRsaWithOAEP(t *testing.T) {
+	testdata := "testdata"
+	encryptkey := entity.EncryptKey{}
+	if err := parser.DecodePrivateKey([]byte(rsaprivatekey), &encryptkey); err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if err := parser.DecodePublicKey([]byte(rsapublickey), &encryptkey); err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	for _, hashType := range []crypto.Hash{crypto.SHA1, crypto.SHA256, crypto.SHA384, crypto.SHA512} {
+		cryptorsa, err := NewCryptoRsaWithOAEP(&encryptkey.RsaKey, hashType, []byte("label"))
+		if err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+		encryptdata, err := cryptorsa.Encrypt([]byte(testdata))
+		if err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+		decryptdata, err := cryptorsa.Decrypt(encryptdata)
+		if err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+		if string(decryptdata) != testdata {
+			t.Fatalf("failed CryptoRsaWithOAEP %s", hashType)
+		}
+		if _, err := rsa.DecryptOAEP(hashType.New(), nil, encryptkey.RsaKey.PrivateKey, encryptdata, []byte("label")); err != nil {
+			t.Fatalf("failed DecryptOAEP %s %#v", hashType, err)
+		}
+		otherlabel, _ := NewCryptoRsaWithOAEP(&encryptkey.RsaKey, hashType, []byte("other"))
+		if _, err := otherlabel.Decrypt(encryptdata); err == nil {
+			t.Fatal("failed Decrypt with other label")
+		} else {
+			t.Logf("failed test %#v", err)
+		}
+		// PKCS #1 v1.5 unpadding of OAEP ciphertext may succeed by chance, but never yields the plaintext
+		if decryptdata, err := NewCryptoRsa(&encryptkey.RsaKey).Decrypt(encryptdata); err == nil && string(decryptdata) == testdata {
+			t.Fatal("failed Decrypt OAEP with PKCS1v15")
+		} else {
+			t.Logf("failed test %#v", err)
+		}
+	}
+	if _, err := NewCryptoRsaWithOAEP(&encryptkey.RsaKey, crypto.MD5, nil); err == nil {
+		t.Fatal("failed NewCryptoRsaWithOAEP ")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	t.Log("success CryptoRsaWithOAEP")
+}
+
+func Test_CryptoRsaHybrid(t *testing.T) {
+	encryptkey := entity.EncryptKey{}
+	if err := parser.DecodePrivateKey([]byte(rsaprivatekey), &encryptkey); err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if err := parser.DecodePublicKey([]byte(rsapublickey), &encryptkey); err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	cryptorsa, err := NewCryptoRsaHybrid(&encryptkey.RsaKey, crypto.SHA256, nil)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	testdata := bytes.Repeat([]byte("0123456789abcdef"), 4096)
+	encryptdata, err := cryptorsa.Encrypt(testdata)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	decryptdata, err := cryptorsa.Decrypt(encryptdata)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if bytes.Equal(decryptdata, testdata) == false {
+		t.Fatal("failed CryptoRsaHybrid ")
+	}
+
+	cryptorsaoaep, err := NewCryptoRsaWithOAEP(&encryptkey.RsaKey, crypto.SHA256, nil)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if decryptdata, err := cryptorsaoaep.Decrypt(encryptdata); err != nil || bytes.Equal(decryptdata, testdata) == false {
+		t.Fatalf("failed Decrypt hybrid with OAEP %#v", err)
+	}
+	if _, err := cryptorsaoaep.Encrypt(testdata); err == nil {
+		t.Fatal("failed Encrypt large data with OAEP")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+
+	if encryptdata[0] != rsaHybridVersion {
+		t.Fatalf("failed CryptoRsaHybrid version marker %x", encryptdata[0])
+	}
+	keySize := encryptkey.RsaKey.PrivateKey.Size()
+	other, err := cryptorsa.Encrypt(testdata)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	spliced := append(append([]byte{}, encryptdata[:1+keySize]...), other[1+keySize:]...)
+	if _, err := cryptorsa.Decrypt(spliced); !errors.Is(err, ErrAuthenticationFailed) {
+		t.Fatalf("failed Decrypt spliced data %#v", err)
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	unmarked := append([]byte{0x02}, encryptdata[1:]...)
+	if _, err := cryptorsa.Decrypt(unmarked); err == nil {
+		t.Fatal("failed Decrypt without version marker")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+
+	encryptdata[len(encryptdata)-1] ^= 0x01
+	if _, err := cryptorsa.Decrypt(encryptdata); !errors.Is(err, ErrAuthenticationFailed) {
+		t.Fatalf("failed Decrypt tampered data %#v", err)
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	t.Log("success CryptoRsaHybrid")
+}
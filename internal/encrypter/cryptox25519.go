@@ -0,0 +1,58 @@
+package encrypter
+
+import (
+	"encoding/base64"
+
+	"github.com/howood/cryptotools/internal/entity"
+)
+
+// CryptoX25519 represents X25519 encryption struct.
+// It uses the same ECIES as CryptoEd25519, so the output is the ephemeral public key (32 bytes) || ChaCha20-Poly1305 ciphertext.
+type CryptoX25519 struct {
+	x25519key *entity.X25519Key
+}
+
+// NewCryptoX25519 create CryptoX25519 struct
+func NewCryptoX25519(x25519key *entity.X25519Key) *CryptoX25519 {
+	return &CryptoX25519{
+		x25519key: x25519key,
+	}
+}
+
+// Encrypt encrypts a input data
+func (cx *CryptoX25519) Encrypt(input []byte) ([]byte, error) {
+	return cx.EncryptWithAAD(input, nil)
+}
+
+// EncryptWithAAD encrypts a input data and authenticates additionalData with it
+func (cx *CryptoX25519) EncryptWithAAD(input, additionalData []byte) ([]byte, error) {
+	return sealX25519(cx.x25519key.PublicKey.Bytes(), input, additionalData)
+}
+
+// Decrypt decrypts a input data
+func (cx *CryptoX25519) Decrypt(input []byte) ([]byte, error) {
+	return cx.DecryptWithAAD(input, nil)
+}
+
+// DecryptWithAAD decrypts a input data and verifies that it was encrypted with additionalData
+func (cx *CryptoX25519) DecryptWithAAD(input, additionalData []byte) ([]byte, error) {
+	return openX25519(cx.x25519key.PrivateKey.Bytes(), cx.x25519key.PublicKey.Bytes(), input, additionalData)
+}
+
+// EncryptWithBase64 encrypts a input data to base64 string
+func (cx *CryptoX25519) EncryptWithBase64(input []byte) (string, error) {
+	ciphertext, err := cx.Encrypt(input)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// DecryptWithBase64 decrypts a input data to base64 string
+func (cx *CryptoX25519) DecryptWithBase64(input string) ([]byte, error) {
+	inputdecoded, err := base64.StdEncoding.DecodeString(input)
+	if err != nil {
+		return nil, err
+	}
+	return cx.Decrypt(inputdecoded)
+}
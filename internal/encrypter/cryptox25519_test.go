@@ -0,0 +1,83 @@
+package encrypter
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"reflect"
+	"testing"
+
+	"github.com/howood/cryptotools/internal/entity"
+	"github.com/howood/cryptotools/internal/parser"
+)
+
+func Test_CryptoX25519(t *testing.T) {
+	testdata := `
+{
+    "message": "ok",
+    "message2": ["ng", "ng2"]
+}
+`
+	privatekey, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	x25519key := &entity.X25519Key{PrivateKey: privatekey, PublicKey: privatekey.PublicKey()}
+	cryptox25519 := NewCryptoX25519(x25519key)
+
+	encryptdata, err := cryptox25519.EncryptWithBase64([]byte(testdata))
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	decryptdata, err := cryptox25519.DecryptWithBase64(encryptdata)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if reflect.DeepEqual(decryptdata, []byte(testdata)) == false {
+		t.Fatal("failed CryptoX25519 ")
+	}
+	encryptaad, err := cryptox25519.EncryptWithAAD([]byte(testdata), []byte("aad"))
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if _, err := cryptox25519.DecryptWithAAD(encryptaad, []byte("other")); err != ErrAuthenticationFailed {
+		t.Fatalf("failed DecryptWithAAD %#v", err)
+	}
+	if _, err := cryptox25519.Decrypt([]byte("short")); err == nil {
+		t.Fatal("failed Decrypt ")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+
+	// ED25519 keys encrypt to their X25519 form, so CryptoX25519 decrypts data encrypted by CryptoEd25519
+	encryptkey := entity.EncryptKey{}
+	if err := parser.DecodePrivateKey([]byte(ed25519privatekey), &encryptkey); err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if err := parser.DecodePublicKey([]byte(ed25519publickey), &encryptkey); err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	cryptoed25519 := NewCryptoEd25519(&encryptkey.Ed25519Key)
+	publickey, err := cryptoed25519.publicKeyToCurve25519()
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	x25519privatekey, err := ecdh.X25519().NewPrivateKey(cryptoed25519.privateKeyToCurve25519())
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if reflect.DeepEqual(x25519privatekey.PublicKey().Bytes(), publickey) == false {
+		t.Fatal("failed X25519 public key conversion")
+	}
+	encryptdata2, err := cryptoed25519.Encrypt([]byte(testdata))
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	decryptdata, err = NewCryptoX25519(&entity.X25519Key{PrivateKey: x25519privatekey, PublicKey: x25519privatekey.PublicKey()}).Decrypt(encryptdata2)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if reflect.DeepEqual(decryptdata, []byte(testdata)) == false {
+		t.Fatal("failed CryptoX25519 with ED25519 ")
+	}
+	t.Log("success CryptoX25519")
+}
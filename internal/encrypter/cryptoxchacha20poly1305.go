@@ -0,0 +1,105 @@
+package encrypter
+
+import (
+	"crypto/cipher"
+	"encoding/base64"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// CryptoXChaCha20Poly1305 represents XChaCha20-Poly1305 encryption struct
+type CryptoXChaCha20Poly1305 struct {
+	key  []byte
+	aead cipher.AEAD
+}
+
+// NewCryptoXChaCha20Poly1305 create CryptoXChaCha20Poly1305 struct
+func NewCryptoXChaCha20Poly1305(encryptionkey []byte) (*CryptoXChaCha20Poly1305, error) {
+	aead, err := chacha20poly1305.NewX(encryptionkey)
+	if err != nil {
+		return nil, fmt.Errorf("Error: NewX(%d bytes) = %s", len(encryptionkey), err)
+	}
+	return &CryptoXChaCha20Poly1305{
+		key:  append([]byte{}, encryptionkey...),
+		aead: aead,
+	}, nil
+}
+
+// Encrypt encrypts a input data with a random 24 bytes nonce prepended to the output
+func (cx *CryptoXChaCha20Poly1305) Encrypt(input []byte) ([]byte, error) {
+	return cx.EncryptWithAAD(input, nil)
+}
+
+// EncryptWithAAD encrypts a input data and authenticates additionalData with it
+func (cx *CryptoXChaCha20Poly1305) EncryptWithAAD(input, additionalData []byte) ([]byte, error) {
+	return sealWithRandomNonce(cx.aead, input, additionalData)
+}
+
+// Decrypt decrypts a input data and verifies its authentication tag
+func (cx *CryptoXChaCha20Poly1305) Decrypt(input []byte) ([]byte, error) {
+	return cx.DecryptWithAAD(input, nil)
+}
+
+// DecryptWithAAD decrypts a input data and verifies that it was encrypted with additionalData
+func (cx *CryptoXChaCha20Poly1305) DecryptWithAAD(input, additionalData []byte) ([]byte, error) {
+	return openWithPrefixedNonce(cx.aead, input, additionalData)
+}
+
+// NonceSize returns the size of the nonce prepended to the output
+func (cx *CryptoXChaCha20Poly1305) NonceSize() int {
+	return cx.aead.NonceSize()
+}
+
+// NewStreamWriter create StreamWriter struct encrypting to w with a key derived from salt
+func (cx *CryptoXChaCha20Poly1305) NewStreamWriter(salt, additionalData []byte, w io.Writer) (*StreamWriter, error) {
+	aead, noncePrefix, err := cx.streamAead(salt)
+	if err != nil {
+		return nil, err
+	}
+	return NewStreamWriter(aead, noncePrefix, additionalData, w)
+}
+
+// NewStreamReader create StreamReader struct decrypting from r with a key derived from salt
+func (cx *CryptoXChaCha20Poly1305) NewStreamReader(salt, additionalData []byte, r io.Reader) (*StreamReader, error) {
+	aead, noncePrefix, err := cx.streamAead(salt)
+	if err != nil {
+		return nil, err
+	}
+	return NewStreamReader(aead, noncePrefix, additionalData, r)
+}
+
+func (cx *CryptoXChaCha20Poly1305) streamAead(salt []byte) (cipher.AEAD, []byte, error) {
+	key, noncePrefix, err := deriveStreamKey(cx.key, salt, StreamNoncePrefixSize(cx.aead))
+	if err != nil {
+		return nil, nil, err
+	}
+	streamEncrypter, err := NewCryptoXChaCha20Poly1305(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return streamEncrypter.aead, noncePrefix, nil
+}
+
+// EncryptWithBase64 encrypts a input data to base64 string
+func (cx *CryptoXChaCha20Poly1305) EncryptWithBase64(input string) (string, error) {
+	ciphertext, err := cx.Encrypt([]byte(input))
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// DecryptWithBase64 decrypts a input data to base64 string
+func (cx *CryptoXChaCha20Poly1305) DecryptWithBase64(input string) (string, error) {
+	inputdecoded, err := base64.StdEncoding.DecodeString(input)
+	if err != nil {
+		return "", err
+	}
+	decrypttext, err := cx.Decrypt(inputdecoded)
+	if err != nil {
+		return "", err
+	}
+	return string(decrypttext), nil
+}
@@ -0,0 +1,84 @@
+package encrypter
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func Test_CryptoXChaCha20Poly1305(t *testing.T) {
+	key := []byte("passw0rdpassw0rdpassw0rdpassw0rd")
+	testdata := `
+{
+    "message": "ok",
+    "message2": ["ng", "ng2"]
+}
+`
+	cryptoxchacha, err := NewCryptoXChaCha20Poly1305(key)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	encryptdata, err := cryptoxchacha.EncryptWithBase64(testdata)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	decryptdata, err := cryptoxchacha.DecryptWithBase64(encryptdata)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if decryptdata != testdata {
+		t.Fatal("failed CryptoXChaCha20Poly1305 ")
+	}
+
+	encryptdata2, err := cryptoxchacha.EncryptWithBase64(testdata)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if encryptdata == encryptdata2 {
+		t.Fatal("failed CryptoXChaCha20Poly1305 nonce is reused")
+	}
+
+	tampered, _ := base64.StdEncoding.DecodeString(encryptdata)
+	if len(tampered) != 24+len(testdata)+16 {
+		t.Fatalf("failed CryptoXChaCha20Poly1305 ciphertext length %d", len(tampered))
+	}
+	tampered[len(tampered)-1] ^= 0x01
+	if _, err := cryptoxchacha.Decrypt(tampered); !errors.Is(err, ErrAuthenticationFailed) {
+		t.Fatalf("failed Decrypt tampered data %#v", err)
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+
+	aad := []byte("row-1")
+	encryptdataaad, err := cryptoxchacha.EncryptWithAAD([]byte(testdata), aad)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if decryptdata, err := cryptoxchacha.DecryptWithAAD(encryptdataaad, aad); err != nil || string(decryptdata) != testdata {
+		t.Fatalf("failed DecryptWithAAD %#v", err)
+	}
+	if _, err := cryptoxchacha.DecryptWithAAD(encryptdataaad, []byte("row-2")); !errors.Is(err, ErrAuthenticationFailed) {
+		t.Fatalf("failed DecryptWithAAD with other additional data %#v", err)
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+
+	if _, err := cryptoxchacha.DecryptWithBase64("aaaaaaa"); err == nil {
+		t.Fatal("failed DecryptWithBase64 ")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	if _, err := cryptoxchacha.Decrypt([]byte("short")); err == nil {
+		t.Fatal("failed Decrypt ")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+
+	if _, err := NewCryptoXChaCha20Poly1305([]byte("sssss")); err == nil {
+		t.Fatal("failed NewCryptoXChaCha20Poly1305 ")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+
+	t.Log("success CryptoXChaCha20Poly1305")
+}
@@ -0,0 +1,214 @@
+package encrypter
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/howood/cryptotools/internal/entity"
+	"golang.org/x/crypto/hkdf"
+)
+
+// ECIES wire format (SEC 1 v2 / ISO 18033-2 ECIES-KEM with DEM):
+//
+//	R || C || T
+//
+// R is the ephemeral public key as an uncompressed SEC 1 point (0x04 || X || Y).
+// Z is the x-coordinate of the ECDH shared point as a fixed length big-endian integer.
+// The keys are derived from Z with R as shared info so that the ciphertext is bound to R:
+//
+//	X9.63-SHA256: K = SHA256(Z || 00000001 || R) || SHA256(Z || 00000002 || R) || ...
+//	HKDF-SHA256:  K = HKDF-SHA256(IKM = Z, salt = empty, info = R)
+//
+// With AES-256-GCM, K is 32 bytes, the nonce is 12 zero bytes as K is unique to R,
+// additional data is the GCM AAD, C is the GCM ciphertext and T is the 16 bytes GCM tag.
+// With AES-256-CTR and HMAC-SHA256, K is 64 bytes, K[:32] is the AES key used with a zero IV,
+// K[32:] is the HMAC key and T = HMAC-SHA256(K[32:], C || additional data || L) is 32 bytes,
+// where L is the bit length of additional data as 8 bytes big-endian integer as DEM1 of ISO 18033-2.
+
+const (
+	eciesAesKeySize  = 32
+	eciesHmacKeySize = 32
+)
+
+func validateEcies(kdf entity.EciesKDF, eciesCipher entity.EciesCipher) error {
+	switch kdf {
+	case entity.EciesKDFX963SHA256, entity.EciesKDFHKDFSHA256:
+	default:
+		return errors.New("Invalid ECIES KDF")
+	}
+	switch eciesCipher {
+	case entity.EciesCipherAes256Gcm, entity.EciesCipherAes256CtrHmacSHA256:
+	default:
+		return errors.New("Invalid ECIES cipher")
+	}
+	return nil
+}
+
+func (ce *CryptoEcdsa) encryptECIES(input, additionalData []byte) ([]byte, error) {
+	publickey, err := ce.ecdsakey.PublicKey.ECDH()
+	if err != nil {
+		return nil, err
+	}
+	ephemeral, err := publickey.Curve().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return ce.encryptECIESWithEphemeral(input, additionalData, ephemeral)
+}
+
+func (ce *CryptoEcdsa) encryptECIESWithEphemeral(input, additionalData []byte, ephemeral *ecdh.PrivateKey) ([]byte, error) {
+	publickey, err := ce.ecdsakey.PublicKey.ECDH()
+	if err != nil {
+		return nil, err
+	}
+	shared, err := ephemeral.ECDH(publickey)
+	if err != nil {
+		return nil, err
+	}
+	ephemeralPublic := ephemeral.PublicKey().Bytes()
+	key, err := ce.deriveEciesKey(shared, ephemeralPublic)
+	if err != nil {
+		return nil, err
+	}
+	switch ce.eciesCipher {
+	case entity.EciesCipherAes256Gcm:
+		aead, err := newEciesGcm(key)
+		if err != nil {
+			return nil, err
+		}
+		return aead.Seal(ephemeralPublic, make([]byte, aead.NonceSize()), input, additionalData), nil
+	case entity.EciesCipherAes256CtrHmacSHA256:
+		out := make([]byte, len(ephemeralPublic)+len(input))
+		copy(out, ephemeralPublic)
+		ct := out[len(ephemeralPublic):]
+		if err := eciesCtr(key[:eciesAesKeySize], ct, input); err != nil {
+			return nil, err
+		}
+		return append(out, eciesHmac(key[eciesAesKeySize:], ct, additionalData)...), nil
+	default:
+		return nil, errors.New("Invalid ECIES cipher")
+	}
+}
+
+func (ce *CryptoEcdsa) decryptECIES(input, additionalData []byte) ([]byte, error) {
+	privatekey, err := ce.ecdsakey.PrivateKey.ECDH()
+	if err != nil {
+		return nil, err
+	}
+	pointSize := len(privatekey.PublicKey().Bytes())
+	tagSize := sha256.Size
+	if ce.eciesCipher == entity.EciesCipherAes256Gcm {
+		tagSize = 16
+	}
+	if len(input) < pointSize+tagSize {
+		return nil, errors.New("Invalid inputdata")
+	}
+	ephemeralPublic := input[:pointSize]
+	ephemeral, err := privatekey.Curve().NewPublicKey(ephemeralPublic)
+	if err != nil {
+		return nil, errors.New("Invalid Key curve")
+	}
+	shared, err := privatekey.ECDH(ephemeral)
+	if err != nil {
+		return nil, err
+	}
+	key, err := ce.deriveEciesKey(shared, ephemeralPublic)
+	if err != nil {
+		return nil, err
+	}
+	switch ce.eciesCipher {
+	case entity.EciesCipherAes256Gcm:
+		aead, err := newEciesGcm(key)
+		if err != nil {
+			return nil, err
+		}
+		decryptData, err := aead.Open(nil, make([]byte, aead.NonceSize()), input[pointSize:], additionalData)
+		if err != nil {
+			return nil, ErrAuthenticationFailed
+		}
+		return decryptData, nil
+	case entity.EciesCipherAes256CtrHmacSHA256:
+		tagStart := len(input) - tagSize
+		ct := input[pointSize:tagStart]
+		if !hmac.Equal(eciesHmac(key[eciesAesKeySize:], ct, additionalData), input[tagStart:]) {
+			return nil, ErrAuthenticationFailed
+		}
+		decryptData := make([]byte, len(ct))
+		if err := eciesCtr(key[:eciesAesKeySize], decryptData, ct); err != nil {
+			return nil, err
+		}
+		return decryptData, nil
+	default:
+		return nil, errors.New("Invalid ECIES cipher")
+	}
+}
+
+// deriveEciesKey derives the data encryption keys from the shared secret with the ephemeral public key as shared info
+func (ce *CryptoEcdsa) deriveEciesKey(shared, sharedInfo []byte) ([]byte, error) {
+	keySize := eciesAesKeySize
+	if ce.eciesCipher == entity.EciesCipherAes256CtrHmacSHA256 {
+		keySize += eciesHmacKeySize
+	}
+	switch ce.eciesKDF {
+	case entity.EciesKDFX963SHA256:
+		return x963KDF(shared, sharedInfo, keySize), nil
+	case entity.EciesKDFHKDFSHA256:
+		key := make([]byte, keySize)
+		if _, err := io.ReadFull(hkdf.New(sha256.New, shared, nil, sharedInfo), key); err != nil {
+			return nil, err
+		}
+		return key, nil
+	default:
+		return nil, errors.New("Invalid ECIES KDF")
+	}
+}
+
+// x963KDF derives keySize bytes with ANSI X9.63 KDF with SHA-256
+func x963KDF(shared, sharedInfo []byte, keySize int) []byte {
+	key := make([]byte, 0, keySize+sha256.Size)
+	counter := make([]byte, 4)
+	for i := uint32(1); len(key) < keySize; i++ {
+		binary.BigEndian.PutUint32(counter, i)
+		h := sha256.New()
+		h.Write(shared)
+		h.Write(counter)
+		h.Write(sharedInfo)
+		key = h.Sum(key)
+	}
+	return key[:keySize]
+}
+
+func newEciesGcm(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func eciesCtr(key, dst, src []byte) error {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return err
+	}
+	cipher.NewCTR(block, make([]byte, aes.BlockSize)).XORKeyStream(dst, src)
+	return nil
+}
+
+func eciesHmac(key, ct, additionalData []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(ct)
+	h.Write(additionalData)
+	// the length of additional data keeps bytes from being moved between ct and additional data
+	var bitlen [8]byte
+	binary.BigEndian.PutUint64(bitlen[:], uint64(len(additionalData))*8)
+	h.Write(bitlen[:])
+	return h.Sum(nil)
+}
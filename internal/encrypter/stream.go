@@ -0,0 +1,207 @@
+package encrypter
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// StreamChunkSize is plaintext size of each chunk of the stream
+const StreamChunkSize = 64 * 1024
+
+// StreamSaltSize is the size of the random salt from which the key of each stream is derived
+const StreamSaltSize = 32
+
+// streamNonceSuffixSize is the size of counter(4) and last chunk flag(1) appended to the nonce prefix
+const streamNonceSuffixSize = 5
+
+// streamHkdfInfo is HKDF info binding derived stream keys to this construction
+var streamHkdfInfo = []byte("cryptotools STREAM-HKDF-SHA256")
+
+var errStreamClosed = errors.New("stream is already closed")
+
+// StreamWriter encrypts data written to it in chunks with STREAM construction.
+// Each chunk nonce is prefix | counter | last chunk flag, so that truncation and reordering are detected.
+// additionalData is authenticated with every chunk.
+type StreamWriter struct {
+	aead           cipher.AEAD
+	nonce          []byte
+	additionalData []byte
+	counter        uint32
+	writer         io.Writer
+	buffer         []byte
+	closed         bool
+}
+
+// StreamReader decrypts data encrypted by StreamWriter
+type StreamReader struct {
+	aead           cipher.AEAD
+	nonce          []byte
+	additionalData []byte
+	counter        uint32
+	reader         *bufio.Reader
+	chunk          []byte
+	plain          []byte
+	done           bool
+}
+
+// NewStreamWriter create StreamWriter struct
+func NewStreamWriter(aead cipher.AEAD, noncePrefix, additionalData []byte, w io.Writer) (*StreamWriter, error) {
+	nonce, err := newStreamNonce(aead, noncePrefix)
+	if err != nil {
+		return nil, err
+	}
+	return &StreamWriter{
+		aead:           aead,
+		nonce:          nonce,
+		additionalData: additionalData,
+		writer:         w,
+		buffer:         make([]byte, 0, StreamChunkSize),
+	}, nil
+}
+
+// NewStreamReader create StreamReader struct
+func NewStreamReader(aead cipher.AEAD, noncePrefix, additionalData []byte, r io.Reader) (*StreamReader, error) {
+	nonce, err := newStreamNonce(aead, noncePrefix)
+	if err != nil {
+		return nil, err
+	}
+	return &StreamReader{
+		aead:           aead,
+		nonce:          nonce,
+		additionalData: additionalData,
+		reader:         bufio.NewReaderSize(r, StreamChunkSize+aead.Overhead()),
+		chunk:          make([]byte, StreamChunkSize+aead.Overhead()),
+	}, nil
+}
+
+// StreamNoncePrefixSize returns nonce prefix size of the stream for AEAD
+func StreamNoncePrefixSize(aead cipher.AEAD) int {
+	return aead.NonceSize() - streamNonceSuffixSize
+}
+
+// Write encrypts input data. A chunk is written only when the next data arrives,
+// so that the last chunk can be sealed on Close.
+func (sw *StreamWriter) Write(input []byte) (int, error) {
+	if sw.closed {
+		return 0, errStreamClosed
+	}
+	written := 0
+	for len(input) > 0 {
+		if len(sw.buffer) == StreamChunkSize {
+			if err := sw.flush(false); err != nil {
+				return written, err
+			}
+		}
+		n := copy(sw.buffer[len(sw.buffer):StreamChunkSize], input)
+		sw.buffer = sw.buffer[:len(sw.buffer)+n]
+		input = input[n:]
+		written += n
+	}
+	return written, nil
+}
+
+// Close writes the last chunk. It does not close the underlying writer.
+func (sw *StreamWriter) Close() error {
+	if sw.closed {
+		return errStreamClosed
+	}
+	sw.closed = true
+	return sw.flush(true)
+}
+
+func (sw *StreamWriter) flush(last bool) error {
+	if sw.counter == math.MaxUint32 {
+		return errors.New("stream is too long")
+	}
+	setStreamNonce(sw.nonce, sw.counter, last)
+	ciphertext := sw.aead.Seal(nil, sw.nonce, sw.buffer, sw.additionalData)
+	if _, err := sw.writer.Write(ciphertext); err != nil {
+		return err
+	}
+	sw.counter++
+	sw.buffer = sw.buffer[:0]
+	return nil
+}
+
+// Read decrypts data. It returns ErrAuthenticationFailed when the stream has been modified,
+// reordered or truncated.
+func (sr *StreamReader) Read(output []byte) (int, error) {
+	for len(sr.plain) == 0 {
+		if sr.done {
+			return 0, io.EOF
+		}
+		if err := sr.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(output, sr.plain)
+	sr.plain = sr.plain[n:]
+	return n, nil
+}
+
+func (sr *StreamReader) next() error {
+	n, err := io.ReadFull(sr.reader, sr.chunk)
+	last := false
+	switch {
+	case err == io.EOF || err == io.ErrUnexpectedEOF:
+		last = true
+	case err != nil:
+		return err
+	default:
+		if _, err := sr.reader.Peek(1); err == io.EOF {
+			last = true
+		} else if err != nil {
+			return err
+		}
+	}
+	if sr.counter == math.MaxUint32 {
+		return errors.New("stream is too long")
+	}
+	setStreamNonce(sr.nonce, sr.counter, last)
+	plain, err := sr.aead.Open(sr.chunk[:0], sr.nonce, sr.chunk[:n], sr.additionalData)
+	if err != nil {
+		return ErrAuthenticationFailed
+	}
+	sr.counter++
+	sr.plain = plain
+	sr.done = last
+	return nil
+}
+
+// deriveStreamKey derives the key and nonce prefix of a stream from key and a random salt with HKDF-SHA256,
+// so that nonces of different streams never collide under the same key.
+func deriveStreamKey(key, salt []byte, noncePrefixSize int) ([]byte, []byte, error) {
+	if len(salt) != StreamSaltSize {
+		return nil, nil, errors.New("Invalid stream salt size")
+	}
+	derived := make([]byte, len(key)+noncePrefixSize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, key, salt, streamHkdfInfo), derived); err != nil {
+		return nil, nil, err
+	}
+	return derived[:len(key)], derived[len(key):], nil
+}
+
+func newStreamNonce(aead cipher.AEAD, noncePrefix []byte) ([]byte, error) {
+	if len(noncePrefix) != StreamNoncePrefixSize(aead) {
+		return nil, errors.New("Invalid stream nonce prefix size")
+	}
+	nonce := make([]byte, aead.NonceSize())
+	copy(nonce, noncePrefix)
+	return nonce, nil
+}
+
+func setStreamNonce(nonce []byte, counter uint32, last bool) {
+	suffix := nonce[len(nonce)-streamNonceSuffixSize:]
+	binary.BigEndian.PutUint32(suffix, counter)
+	suffix[4] = 0
+	if last {
+		suffix[4] = 1
+	}
+}
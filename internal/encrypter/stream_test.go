@@ -0,0 +1,124 @@
+package encrypter
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func Test_Stream(t *testing.T) {
+	key := []byte("passw0rdpassw0rdpassw0rdpassw0rd")
+	cryptoaesgcm, err := NewCryptoAesGcm(key)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	salt, err := makeRandomData(StreamSaltSize)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	for _, size := range []int{0, 1, StreamChunkSize - 1, StreamChunkSize, StreamChunkSize*2 + 100} {
+		testdata, _ := makeRandomData(size)
+		var encrypted bytes.Buffer
+		sw, err := cryptoaesgcm.NewStreamWriter(salt, nil, &encrypted)
+		if err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+		if _, err := io.Copy(sw, bytes.NewReader(testdata)); err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+		if err := sw.Close(); err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+		if _, err := sw.Write([]byte("a")); err == nil {
+			t.Fatal("failed Write after Close")
+		}
+
+		sr, err := cryptoaesgcm.NewStreamReader(salt, nil, bytes.NewReader(encrypted.Bytes()))
+		if err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+		decrypted, err := io.ReadAll(sr)
+		if err != nil {
+			t.Fatalf("failed test %d %#v", size, err)
+		}
+		if bytes.Equal(decrypted, testdata) == false {
+			t.Fatalf("failed Stream %d", size)
+		}
+
+		truncated := encrypted.Bytes()[:encrypted.Len()-1]
+		if size > StreamChunkSize {
+			truncated = encrypted.Bytes()[:StreamChunkSize+cryptoaesgcm.aead.Overhead()]
+		}
+		sr, _ = cryptoaesgcm.NewStreamReader(salt, nil, bytes.NewReader(truncated))
+		if _, err := io.ReadAll(sr); !errors.Is(err, ErrAuthenticationFailed) {
+			t.Fatalf("failed Stream truncated %d %#v", size, err)
+		}
+	}
+
+	testdata, _ := makeRandomData(StreamChunkSize * 3)
+	var encrypted bytes.Buffer
+	sw, _ := cryptoaesgcm.NewStreamWriter(salt, nil, &encrypted)
+	sw.Write(testdata)
+	sw.Close()
+	chunksize := StreamChunkSize + cryptoaesgcm.aead.Overhead()
+	data := encrypted.Bytes()
+	reordered := append(append(append([]byte{}, data[chunksize:2*chunksize]...), data[:chunksize]...), data[2*chunksize:]...)
+	sr, _ := cryptoaesgcm.NewStreamReader(salt, nil, bytes.NewReader(reordered))
+	if _, err := io.ReadAll(sr); !errors.Is(err, ErrAuthenticationFailed) {
+		t.Fatalf("failed Stream reordered %#v", err)
+	}
+
+	encrypted.Reset()
+	sw, _ = cryptoaesgcm.NewStreamWriter(salt, []byte("header"), &encrypted)
+	sw.Write(testdata)
+	sw.Close()
+	sr, _ = cryptoaesgcm.NewStreamReader(salt, []byte("HEADER"), bytes.NewReader(encrypted.Bytes()))
+	if _, err := io.ReadAll(sr); !errors.Is(err, ErrAuthenticationFailed) {
+		t.Fatalf("failed Stream additionalData %#v", err)
+	}
+
+	if _, err := cryptoaesgcm.NewStreamWriter([]byte("short"), nil, &encrypted); err == nil {
+		t.Fatal("failed NewStreamWriter ")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	t.Log("success Stream")
+}
+
+func Test_StreamKeyDerivation(t *testing.T) {
+	key := []byte("passw0rdpassw0rdpassw0rdpassw0rd")
+	cryptoaesgcm, err := NewCryptoAesGcm(key)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	cryptoxchacha20poly1305, err := NewCryptoXChaCha20Poly1305(key)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	testdata, _ := makeRandomData(StreamChunkSize + 100)
+	for _, streamEncrypter := range []interface {
+		NewStreamWriter(salt, additionalData []byte, w io.Writer) (*StreamWriter, error)
+		NewStreamReader(salt, additionalData []byte, r io.Reader) (*StreamReader, error)
+	}{cryptoaesgcm, cryptoxchacha20poly1305} {
+		var encrypted [2]bytes.Buffer
+		var salts [2][]byte
+		for i := range encrypted {
+			salts[i], _ = makeRandomData(StreamSaltSize)
+			sw, err := streamEncrypter.NewStreamWriter(salts[i], nil, &encrypted[i])
+			if err != nil {
+				t.Fatalf("failed test %#v", err)
+			}
+			sw.Write(testdata)
+			sw.Close()
+		}
+		if bytes.Equal(encrypted[0].Bytes(), encrypted[1].Bytes()) {
+			t.Fatal("failed Stream ciphertexts of the same plaintext are equal")
+		}
+		sr, _ := streamEncrypter.NewStreamReader(salts[1], nil, bytes.NewReader(encrypted[0].Bytes()))
+		if _, err := io.ReadAll(sr); !errors.Is(err, ErrAuthenticationFailed) {
+			t.Fatalf("failed Stream with another salt %#v", err)
+		}
+	}
+	t.Log("success StreamKeyDerivation")
+}
@@ -7,13 +7,26 @@ import (
 	"errors"
 )
 
-// removePaddingBlock removes padding from data that was added with
+// ErrAuthenticationFailed is returned when authenticated decryption fails
+var ErrAuthenticationFailed = errors.New("message authentication failed: ciphertext has been modified or key is wrong")
+
+// removePaddingBlock removes padding from data that was added with addPaddingBlock.
+// The padding is zero bytes followed by a byte holding the padding length.
 func removePaddingBlock(b []byte) ([]byte, error) {
+	if len(b) == 0 || len(b)%aes.BlockSize != 0 {
+		return nil, errors.New("Padding incorrect")
+	}
 	l := int(b[len(b)-1])
-	if l > 16 {
+	if l == 0 || l > aes.BlockSize {
+		return nil, errors.New("Padding incorrect")
+	}
+	var invalid byte
+	for _, p := range b[len(b)-l : len(b)-1] {
+		invalid |= p
+	}
+	if invalid != 0 {
 		return nil, errors.New("Padding incorrect")
 	}
-
 	return b[:len(b)-l], nil
 }
 
@@ -61,3 +74,25 @@ func makeRandomData(length int) ([]byte, error) {
 	_, err := rand.Read(bytes)
 	return bytes, err
 }
+
+// sealWithRandomNonce encrypts with AEAD and prepends a random nonce to the ciphertext.
+func sealWithRandomNonce(aead cipher.AEAD, input, additionalData []byte) ([]byte, error) {
+	nonce, err := makeRandomData(aead.NonceSize())
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, input, additionalData), nil
+}
+
+// openWithPrefixedNonce decrypts AEAD ciphertext which has the nonce prepended.
+func openWithPrefixedNonce(aead cipher.AEAD, input, additionalData []byte) ([]byte, error) {
+	if len(input) < aead.NonceSize()+aead.Overhead() {
+		return nil, errors.New("Invalid inputdata")
+	}
+	nonce := input[:aead.NonceSize()]
+	decrypttext, err := aead.Open(nil, nonce, input[aead.NonceSize():], additionalData)
+	if err != nil {
+		return nil, ErrAuthenticationFailed
+	}
+	return decrypttext, nil
+}
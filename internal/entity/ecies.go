@@ -0,0 +1,21 @@
+package entity
+
+// EciesKDF is ECIES key derivation function
+type EciesKDF string
+
+const (
+	// EciesKDFX963SHA256 is ANSI X9.63 KDF with SHA-256
+	EciesKDFX963SHA256 EciesKDF = "x963-sha256"
+	// EciesKDFHKDFSHA256 is HKDF with SHA-256
+	EciesKDFHKDFSHA256 EciesKDF = "hkdf-sha256"
+)
+
+// EciesCipher is ECIES data encryption
+type EciesCipher string
+
+const (
+	// EciesCipherAes256Gcm is AES-256-GCM
+	EciesCipherAes256Gcm EciesCipher = "aes-256-gcm"
+	// EciesCipherAes256CtrHmacSHA256 is AES-256-CTR with HMAC-SHA256
+	EciesCipherAes256CtrHmacSHA256 EciesCipher = "aes-256-ctr-hmac-sha256"
+)
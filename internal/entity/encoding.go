@@ -0,0 +1,19 @@
+package entity
+
+// Encoding is text encoding of ciphertext
+type Encoding string
+
+const (
+	// EncodingBase64Std is standard base64 encoding. It is the default
+	EncodingBase64Std Encoding = "base64"
+	// EncodingBase64URL is URL-safe base64 encoding with padding
+	EncodingBase64URL Encoding = "base64url"
+	// EncodingBase64RawURL is URL-safe base64 encoding without padding
+	EncodingBase64RawURL Encoding = "base64rawurl"
+	// EncodingHex is lowercase hex encoding
+	EncodingHex Encoding = "hex"
+	// EncodingBase32 is standard base32 encoding
+	EncodingBase32 Encoding = "base32"
+	// EncodingRaw is no encoding, the ciphertext bytes are converted to string as is
+	EncodingRaw Encoding = "raw"
+)
@@ -10,6 +10,8 @@ const (
 	EncryptTypeECDSA EncryptKeyType = "ecdsa"
 	// EncryptTypeED25519 is ED25519 KeyType
 	EncryptTypeED25519 EncryptKeyType = "ed25519"
+	// EncryptTypeX25519 is X25519 KeyType
+	EncryptTypeX25519 EncryptKeyType = "x25519"
 )
 
 // EncryptKey represents private & public key
@@ -18,4 +20,9 @@ type EncryptKey struct {
 	RsaKey     RsaKey
 	EcdsaKey   EcdsaKey
 	Ed25519Key Ed25519Key
+	X25519Key  X25519Key
+	// Comment is the comment of an OpenSSH key
+	Comment string
+	// Options is the options of an OpenSSH authorized_keys line
+	Options []string
 }
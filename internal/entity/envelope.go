@@ -0,0 +1,23 @@
+package entity
+
+// CipherAlgorithm is common key cipher algorithm ID stored in Envelope
+type CipherAlgorithm uint8
+
+const (
+	// CipherAlgorithmAesGcm is AES-GCM algorithm ID
+	CipherAlgorithmAesGcm CipherAlgorithm = 0x01
+	// CipherAlgorithmXChaCha20Poly1305 is XChaCha20-Poly1305 algorithm ID
+	CipherAlgorithmXChaCha20Poly1305 CipherAlgorithm = 0x02
+	// CipherAlgorithmAesSiv is deterministic AES-SIV algorithm ID
+	CipherAlgorithmAesSiv CipherAlgorithm = 0x03
+)
+
+// Envelope represents versioned common key ciphertext
+type Envelope struct {
+	Version       uint8
+	Algorithm     CipherAlgorithm
+	KeyID         string
+	KeyDerivation *KeyDerivation
+	Nonce         []byte
+	Ciphertext    []byte
+}
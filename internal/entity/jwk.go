@@ -0,0 +1,13 @@
+package entity
+
+// JWKOptions represents parameters of exported JWK
+type JWKOptions struct {
+	// KeyID is kid
+	KeyID string
+	// Algorithm is alg such as RS256, PS256, RSA-OAEP-256, ES256, ECDH-ES or EdDSA
+	Algorithm string
+	// Use is use, sig or enc
+	Use string
+	// KeyOps is key_ops such as sign, verify, encrypt, decrypt, wrapKey, unwrapKey, deriveKey and deriveBits
+	KeyOps []string
+}
@@ -0,0 +1,27 @@
+package entity
+
+// KeyDerivationAlgorithm is passphrase key derivation algorithm ID stored in Envelope
+type KeyDerivationAlgorithm uint8
+
+const (
+	// KeyDerivationNone is used when the common key is given directly
+	KeyDerivationNone KeyDerivationAlgorithm = 0x00
+	// KeyDerivationArgon2id is Argon2id key derivation
+	KeyDerivationArgon2id KeyDerivationAlgorithm = 0x01
+	// KeyDerivationScrypt is scrypt key derivation
+	KeyDerivationScrypt KeyDerivationAlgorithm = 0x02
+	// KeyDerivationPBKDF2SHA256 is PBKDF2 with HMAC-SHA256 key derivation
+	KeyDerivationPBKDF2SHA256 KeyDerivationAlgorithm = 0x03
+)
+
+// KeyDerivation represents passphrase key derivation parameters.
+// Iterations is time for Argon2id, N for scrypt and iteration count for PBKDF2.
+// Memory is memory size in KiB for Argon2id and r for scrypt.
+// Parallelism is threads for Argon2id and p for scrypt.
+type KeyDerivation struct {
+	Algorithm   KeyDerivationAlgorithm
+	Salt        []byte
+	Iterations  uint32
+	Memory      uint32
+	Parallelism uint8
+}
@@ -0,0 +1,19 @@
+package entity
+
+// KeyFormat is serialization format of key
+type KeyFormat string
+
+const (
+	// KeyFormatPEM is PEM encoded key
+	KeyFormatPEM KeyFormat = "pem"
+	// KeyFormatDER is DER encoded PKCS1, SEC1, PKCS8 or PKIX key
+	KeyFormatDER KeyFormat = "der"
+	// KeyFormatJWK is JSON Web Key
+	KeyFormatJWK KeyFormat = "jwk"
+	// KeyFormatJWKS is JSON Web Key Set
+	KeyFormatJWKS KeyFormat = "jwks"
+	// KeyFormatOpenSSH is OpenSSH private key
+	KeyFormatOpenSSH KeyFormat = "openssh"
+	// KeyFormatAuthorizedKey is OpenSSH authorized_keys line
+	KeyFormatAuthorizedKey KeyFormat = "authorized_key"
+)
@@ -0,0 +1,27 @@
+package entity
+
+// PrivateKeyKDF is key derivation function of PKCS #8 encrypted private key
+type PrivateKeyKDF string
+
+const (
+	// PrivateKeyKDFScrypt is scrypt defined in RFC 7914
+	PrivateKeyKDFScrypt PrivateKeyKDF = "scrypt"
+	// PrivateKeyKDFPBKDF2SHA256 is PBKDF2 with HMAC-SHA256 defined in RFC 8018
+	PrivateKeyKDFPBKDF2SHA256 PrivateKeyKDF = "pbkdf2-sha256"
+)
+
+// PrivateKeyCipher is encryption scheme of PKCS #8 encrypted private key
+type PrivateKeyCipher string
+
+const (
+	// PrivateKeyCipherAes256Cbc is AES-256-CBC
+	PrivateKeyCipherAes256Cbc PrivateKeyCipher = "aes-256-cbc"
+	// PrivateKeyCipherAes256Gcm is AES-256-GCM defined in RFC 5084
+	PrivateKeyCipherAes256Gcm PrivateKeyCipher = "aes-256-gcm"
+)
+
+// PrivateKeyEncryption represents PKCS #8 PBES2 encryption parameters
+type PrivateKeyEncryption struct {
+	KDF    PrivateKeyKDF
+	Cipher PrivateKeyCipher
+}
@@ -0,0 +1,21 @@
+package entity
+
+// RsaSignatureScheme is RSA signature scheme
+type RsaSignatureScheme string
+
+const (
+	// RsaSignatureSchemePSS is RSASSA-PSS
+	RsaSignatureSchemePSS RsaSignatureScheme = "pss"
+	// RsaSignatureSchemePKCS1v15 is RSASSA-PKCS1-v1_5
+	RsaSignatureSchemePKCS1v15 RsaSignatureScheme = "pkcs1v15"
+)
+
+// EcdsaSignatureFormat is ECDSA signature encoding
+type EcdsaSignatureFormat string
+
+const (
+	// EcdsaSignatureFormatASN1 is ASN.1 DER encoded signature as in X.509 and TLS
+	EcdsaSignatureFormatASN1 EcdsaSignatureFormat = "asn1"
+	// EcdsaSignatureFormatRaw is fixed length r || s as in JWS and WebCrypto
+	EcdsaSignatureFormatRaw EcdsaSignatureFormat = "raw"
+)
@@ -0,0 +1,11 @@
+package entity
+
+import (
+	"crypto/ecdh"
+)
+
+// X25519Key represents X25519 private & public key
+type X25519Key struct {
+	PrivateKey *ecdh.PrivateKey
+	PublicKey  *ecdh.PublicKey
+}
@@ -0,0 +1,81 @@
+package generator
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+
+	"github.com/howood/cryptotools/internal/entity"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	commonKeySaltSize = 16
+
+	// upper limits for parameters read from ciphertext, to avoid exhausting resources.
+	// They are a few times the defaults of GenerateKeyDerivation and are checked before deriving.
+	maxArgon2idIterations  = 4
+	maxArgon2idMemory      = 256 * 1024
+	maxArgon2idParallelism = 16
+	maxScryptCost          = 1 << 18
+	maxScryptBlockSize     = 8
+	maxScryptParallelism   = 4
+	maxPBKDF2Iterations    = 10000000
+	errorInvalidDerivation = "Invalid key derivation parameters"
+)
+
+// GenerateKeyDerivation generates key derivation parameters with random salt and default cost
+func GenerateKeyDerivation(algorithm entity.KeyDerivationAlgorithm) (*entity.KeyDerivation, error) {
+	keyderivation := &entity.KeyDerivation{
+		Algorithm: algorithm,
+		Salt:      make([]byte, commonKeySaltSize),
+	}
+	switch algorithm {
+	case entity.KeyDerivationArgon2id:
+		keyderivation.Iterations = 3
+		keyderivation.Memory = 64 * 1024
+		keyderivation.Parallelism = 4
+	case entity.KeyDerivationScrypt:
+		keyderivation.Iterations = 1 << 15
+		keyderivation.Memory = 8
+		keyderivation.Parallelism = 1
+	case entity.KeyDerivationPBKDF2SHA256:
+		keyderivation.Iterations = 600000
+	default:
+		return nil, errors.New(errorInvalidDerivation)
+	}
+	if _, err := rand.Read(keyderivation.Salt); err != nil {
+		return nil, err
+	}
+	return keyderivation, nil
+}
+
+// DeriveCommonKey derives common key from passphrase with key derivation parameters
+func DeriveCommonKey(passphrase []byte, keyderivation *entity.KeyDerivation, keylen int) ([]byte, error) {
+	if len(keyderivation.Salt) == 0 || keyderivation.Iterations == 0 {
+		return nil, errors.New(errorInvalidDerivation)
+	}
+	switch keyderivation.Algorithm {
+	case entity.KeyDerivationArgon2id:
+		if keyderivation.Iterations > maxArgon2idIterations || keyderivation.Memory > maxArgon2idMemory ||
+			keyderivation.Parallelism == 0 || keyderivation.Parallelism > maxArgon2idParallelism {
+			return nil, errors.New(errorInvalidDerivation)
+		}
+		return argon2.IDKey(passphrase, keyderivation.Salt, keyderivation.Iterations, keyderivation.Memory, keyderivation.Parallelism, uint32(keylen)), nil
+	case entity.KeyDerivationScrypt:
+		if keyderivation.Iterations > maxScryptCost || keyderivation.Memory > maxScryptBlockSize ||
+			keyderivation.Parallelism == 0 || keyderivation.Parallelism > maxScryptParallelism {
+			return nil, errors.New(errorInvalidDerivation)
+		}
+		return scrypt.Key(passphrase, keyderivation.Salt, int(keyderivation.Iterations), int(keyderivation.Memory), int(keyderivation.Parallelism), keylen)
+	case entity.KeyDerivationPBKDF2SHA256:
+		if keyderivation.Iterations > maxPBKDF2Iterations {
+			return nil, errors.New(errorInvalidDerivation)
+		}
+		return pbkdf2.Key(passphrase, keyderivation.Salt, int(keyderivation.Iterations), keylen, sha256.New), nil
+	default:
+		return nil, errors.New(errorInvalidDerivation)
+	}
+}
@@ -0,0 +1,59 @@
+package generator
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/howood/cryptotools/internal/entity"
+)
+
+func Test_CommonKeyGenerator(t *testing.T) {
+	passphrase := []byte("passw0rd")
+	for _, algorithm := range []entity.KeyDerivationAlgorithm{entity.KeyDerivationArgon2id, entity.KeyDerivationScrypt, entity.KeyDerivationPBKDF2SHA256} {
+		keyderivation, err := GenerateKeyDerivation(algorithm)
+		if err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+		key, err := DeriveCommonKey(passphrase, keyderivation, 32)
+		if err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+		if len(key) != 32 {
+			t.Fatalf("failed DeriveCommonKey length %d", len(key))
+		}
+		key2, err := DeriveCommonKey(passphrase, keyderivation, 32)
+		if err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+		if reflect.DeepEqual(key, key2) == false {
+			t.Fatal("failed DeriveCommonKey is not stable")
+		}
+		other, err := GenerateKeyDerivation(algorithm)
+		if err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+		if reflect.DeepEqual(keyderivation.Salt, other.Salt) {
+			t.Fatal("failed GenerateKeyDerivation salt is reused")
+		}
+	}
+	if _, err := GenerateKeyDerivation(entity.KeyDerivationNone); err == nil {
+		t.Fatal("failed GenerateKeyDerivation ")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	for _, keyderivation := range []*entity.KeyDerivation{
+		{Algorithm: entity.KeyDerivationArgon2id, Salt: []byte("salt"), Iterations: 1, Memory: 1 << 30, Parallelism: 1},
+		{Algorithm: entity.KeyDerivationArgon2id, Salt: []byte("salt"), Iterations: 5, Memory: 64 * 1024, Parallelism: 1},
+		{Algorithm: entity.KeyDerivationArgon2id, Salt: []byte("salt"), Iterations: 1, Memory: 64 * 1024, Parallelism: 17},
+		{Algorithm: entity.KeyDerivationScrypt, Salt: []byte("salt"), Iterations: 1 << 20, Memory: 8, Parallelism: 1},
+		{Algorithm: entity.KeyDerivationScrypt, Salt: []byte("salt"), Iterations: 1 << 15, Memory: 8, Parallelism: 255},
+		{Algorithm: entity.KeyDerivationPBKDF2SHA256, Salt: []byte("salt"), Iterations: 1 << 30},
+	} {
+		if _, err := DeriveCommonKey(passphrase, keyderivation, 32); err == nil {
+			t.Fatalf("failed DeriveCommonKey with too large parameters %#v", keyderivation)
+		} else {
+			t.Logf("failed test %#v", err)
+		}
+	}
+	t.Log("success CommonKeyGenerator")
+}
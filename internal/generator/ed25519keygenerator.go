@@ -1,6 +1,7 @@
 package generator
 
 import (
+	"crypto/ecdh"
 	"crypto/ed25519"
 	"crypto/rand"
 	"crypto/x509"
@@ -62,3 +63,12 @@ func GenerateEncryptedED25519DER() ([]byte, []byte, error) {
 func GenerateED25519Keys() (ed25519.PublicKey, ed25519.PrivateKey, error) {
 	return ed25519.GenerateKey(rand.Reader)
 }
+
+// GenerateX25519Keys generates X25519 private key and public key
+func GenerateX25519Keys() (*ecdh.PrivateKey, *ecdh.PublicKey, error) {
+	privatekey, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	return privatekey, privatekey.PublicKey(), nil
+}
@@ -19,3 +19,14 @@ func Test_ED25519KeyGenerator(t *testing.T) {
 	t.Log(string(pub))
 	t.Log("success ED25519KeyGenerator")
 }
+
+func Test_X25519KeyGenerator(t *testing.T) {
+	pri, pub, err := GenerateX25519Keys()
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if !pri.PublicKey().Equal(pub) {
+		t.Fatal("failed X25519KeyGenerator")
+	}
+	t.Log("success X25519KeyGenerator")
+}
@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+
+	"github.com/howood/cryptotools/internal/entity"
+)
+
+const errorInvalidEncoding = "Invalid encoding"
+
+// ValidateEncoding checks that encoding is supported. An empty encoding is standard base64.
+func ValidateEncoding(encoding entity.Encoding) error {
+	switch encoding {
+	case "", entity.EncodingBase64Std, entity.EncodingBase64URL, entity.EncodingBase64RawURL,
+		entity.EncodingHex, entity.EncodingBase32, entity.EncodingRaw:
+		return nil
+	default:
+		return errors.New(errorInvalidEncoding)
+	}
+}
+
+// EncodeWithEncoding encodes data to string with encoding. An empty encoding is standard base64.
+func EncodeWithEncoding(data []byte, encoding entity.Encoding) (string, error) {
+	switch encoding {
+	case "", entity.EncodingBase64Std:
+		return base64.StdEncoding.EncodeToString(data), nil
+	case entity.EncodingBase64URL:
+		return base64.URLEncoding.EncodeToString(data), nil
+	case entity.EncodingBase64RawURL:
+		return base64.RawURLEncoding.EncodeToString(data), nil
+	case entity.EncodingHex:
+		return hex.EncodeToString(data), nil
+	case entity.EncodingBase32:
+		return base32.StdEncoding.EncodeToString(data), nil
+	case entity.EncodingRaw:
+		return string(data), nil
+	default:
+		return "", errors.New(errorInvalidEncoding)
+	}
+}
+
+// DecodeWithEncoding decodes string encoded with encoding. An empty encoding is standard base64.
+func DecodeWithEncoding(input string, encoding entity.Encoding) ([]byte, error) {
+	switch encoding {
+	case "", entity.EncodingBase64Std:
+		return base64.StdEncoding.DecodeString(input)
+	case entity.EncodingBase64URL:
+		return base64.URLEncoding.DecodeString(input)
+	case entity.EncodingBase64RawURL:
+		return base64.RawURLEncoding.DecodeString(input)
+	case entity.EncodingHex:
+		return hex.DecodeString(input)
+	case entity.EncodingBase32:
+		return base32.StdEncoding.DecodeString(input)
+	case entity.EncodingRaw:
+		return []byte(input), nil
+	default:
+		return nil, errors.New(errorInvalidEncoding)
+	}
+}
@@ -0,0 +1,53 @@
+package parser
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/howood/cryptotools/internal/entity"
+)
+
+func Test_EncodeWithEncoding(t *testing.T) {
+	data := []byte{0xfb, 0xff, 0x00, 0x10, 0x3e}
+	checkdata := map[entity.Encoding]string{
+		"":                          "+/8AED4=",
+		entity.EncodingBase64Std:    "+/8AED4=",
+		entity.EncodingBase64URL:    "-_8AED4=",
+		entity.EncodingBase64RawURL: "-_8AED4",
+		entity.EncodingHex:          "fbff00103e",
+		entity.EncodingBase32:       "7P7QAEB6",
+		entity.EncodingRaw:          string(data),
+	}
+	for encoding, check := range checkdata {
+		encoded, err := EncodeWithEncoding(data, encoding)
+		if err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+		if encoded != check {
+			t.Fatalf("failed EncodeWithEncoding %s %s", encoding, encoded)
+		}
+		decoded, err := DecodeWithEncoding(encoded, encoding)
+		if err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+		if bytes.Equal(decoded, data) == false {
+			t.Fatalf("failed DecodeWithEncoding %s", encoding)
+		}
+	}
+	if _, err := DecodeWithEncoding("zz", entity.EncodingHex); err == nil {
+		t.Fatal("failed DecodeWithEncoding ")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	if _, err := EncodeWithEncoding(data, "base58"); err == nil {
+		t.Fatal("failed EncodeWithEncoding ")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	if err := ValidateEncoding("base58"); err == nil {
+		t.Fatal("failed ValidateEncoding ")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	t.Log("success EncodeWithEncoding")
+}
@@ -0,0 +1,177 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/howood/cryptotools/internal/entity"
+)
+
+// Envelope layout (version 1):
+//
+//	magic(1) | version(1) | algorithm(1) | keyid length(1) | keyid | key derivation | nonce length(1) | nonce | ciphertext
+//
+// key derivation is:
+//
+//	kdf algorithm(1) | [salt length(1) | salt | iterations(4) | memory(4) | parallelism(1)] when kdf algorithm is not none
+//
+// The header before the nonce is authenticated as associated data of the ciphertext.
+const (
+	envelopeMagic     byte  = 0xc7
+	envelopeVersion   uint8 = 0x01
+	envelopeMaxLength       = 0xff
+)
+
+// ErrInvalidEnvelope is returned when input data is not an envelope
+var ErrInvalidEnvelope = errors.New("invalid envelope data")
+
+// UnsupportedEnvelopeVersionError is returned when envelope version is unknown
+type UnsupportedEnvelopeVersionError struct {
+	Version uint8
+}
+
+func (e *UnsupportedEnvelopeVersionError) Error() string {
+	return fmt.Sprintf("unsupported envelope version : %d", e.Version)
+}
+
+// MarshalEnvelope marshals envelope to bytes
+func MarshalEnvelope(envelope *entity.Envelope) ([]byte, error) {
+	out, err := MarshalEnvelopeHeader(envelope)
+	if err != nil {
+		return nil, err
+	}
+	if len(envelope.Nonce) > envelopeMaxLength {
+		return nil, errors.New("envelope nonce is too long")
+	}
+	out = append(out, byte(len(envelope.Nonce)))
+	out = append(out, envelope.Nonce...)
+	out = append(out, envelope.Ciphertext...)
+	return out, nil
+}
+
+// MarshalEnvelopeHeader marshals envelope fields before the nonce to bytes.
+// It is the associated data authenticated with the ciphertext.
+func MarshalEnvelopeHeader(envelope *entity.Envelope) ([]byte, error) {
+	if envelope.Version != envelopeVersion {
+		return nil, &UnsupportedEnvelopeVersionError{Version: envelope.Version}
+	}
+	if len(envelope.KeyID) > envelopeMaxLength {
+		return nil, errors.New("envelope keyid is too long")
+	}
+	out := make([]byte, 0, 24+len(envelope.KeyID)+len(envelope.Nonce)+len(envelope.Ciphertext))
+	out = append(out, envelopeMagic, envelope.Version, byte(envelope.Algorithm))
+	out = append(out, byte(len(envelope.KeyID)))
+	out = append(out, envelope.KeyID...)
+	keyderivation := envelope.KeyDerivation
+	if keyderivation == nil || keyderivation.Algorithm == entity.KeyDerivationNone {
+		out = append(out, byte(entity.KeyDerivationNone))
+	} else {
+		if len(keyderivation.Salt) > envelopeMaxLength {
+			return nil, errors.New("envelope salt is too long")
+		}
+		out = append(out, byte(keyderivation.Algorithm), byte(len(keyderivation.Salt)))
+		out = append(out, keyderivation.Salt...)
+		out = binary.BigEndian.AppendUint32(out, keyderivation.Iterations)
+		out = binary.BigEndian.AppendUint32(out, keyderivation.Memory)
+		out = append(out, keyderivation.Parallelism)
+	}
+	return out, nil
+}
+
+// EnvelopeAdditionalData returns the associated data authenticated with the envelope ciphertext,
+// which is the marshaled header followed by additionalData.
+func EnvelopeAdditionalData(envelope *entity.Envelope, additionalData []byte) ([]byte, error) {
+	header, err := MarshalEnvelopeHeader(envelope)
+	if err != nil {
+		return nil, err
+	}
+	return append(header, additionalData...), nil
+}
+
+// UnmarshalEnvelope unmarshals bytes to envelope
+func UnmarshalEnvelope(input []byte) (*entity.Envelope, error) {
+	reader := bytes.NewReader(input)
+	envelope, err := ReadEnvelopeHeader(reader)
+	if err != nil {
+		return nil, err
+	}
+	envelope.Ciphertext = input[len(input)-reader.Len():]
+	return envelope, nil
+}
+
+// ReadEnvelopeHeader reads envelope fields before ciphertext from reader.
+// It is used for streams whose ciphertext follows the header.
+func ReadEnvelopeHeader(reader io.Reader) (*entity.Envelope, error) {
+	header := make([]byte, 3)
+	if _, err := io.ReadFull(reader, header[:2]); err != nil || header[0] != envelopeMagic {
+		return nil, envelopeReadError(err)
+	}
+	envelope := &entity.Envelope{Version: header[1]}
+	if envelope.Version != envelopeVersion {
+		return nil, &UnsupportedEnvelopeVersionError{Version: envelope.Version}
+	}
+	if _, err := io.ReadFull(reader, header[2:]); err != nil {
+		return nil, envelopeReadError(err)
+	}
+	envelope.Algorithm = entity.CipherAlgorithm(header[2])
+	keyid, err := readEnvelopeField(reader)
+	if err != nil {
+		return nil, err
+	}
+	envelope.KeyID = string(keyid)
+	if envelope.KeyDerivation, err = readEnvelopeKeyDerivation(reader); err != nil {
+		return nil, err
+	}
+	if envelope.Nonce, err = readEnvelopeField(reader); err != nil {
+		return nil, err
+	}
+	return envelope, nil
+}
+
+// readEnvelopeField reads one byte length prefixed field
+func readEnvelopeField(reader io.Reader) ([]byte, error) {
+	length := make([]byte, 1)
+	if _, err := io.ReadFull(reader, length); err != nil {
+		return nil, envelopeReadError(err)
+	}
+	field := make([]byte, int(length[0]))
+	if _, err := io.ReadFull(reader, field); err != nil {
+		return nil, envelopeReadError(err)
+	}
+	return field, nil
+}
+
+// readEnvelopeKeyDerivation reads key derivation parameters
+func readEnvelopeKeyDerivation(reader io.Reader) (*entity.KeyDerivation, error) {
+	algorithm := make([]byte, 1)
+	if _, err := io.ReadFull(reader, algorithm); err != nil {
+		return nil, envelopeReadError(err)
+	}
+	keyderivation := &entity.KeyDerivation{Algorithm: entity.KeyDerivationAlgorithm(algorithm[0])}
+	if keyderivation.Algorithm == entity.KeyDerivationNone {
+		return nil, nil
+	}
+	var err error
+	if keyderivation.Salt, err = readEnvelopeField(reader); err != nil {
+		return nil, err
+	}
+	params := make([]byte, 9)
+	if _, err := io.ReadFull(reader, params); err != nil {
+		return nil, envelopeReadError(err)
+	}
+	keyderivation.Iterations = binary.BigEndian.Uint32(params[0:4])
+	keyderivation.Memory = binary.BigEndian.Uint32(params[4:8])
+	keyderivation.Parallelism = params[8]
+	return keyderivation, nil
+}
+
+// envelopeReadError converts short read to ErrInvalidEnvelope
+func envelopeReadError(err error) error {
+	if err == nil || err == io.EOF || err == io.ErrUnexpectedEOF {
+		return ErrInvalidEnvelope
+	}
+	return err
+}
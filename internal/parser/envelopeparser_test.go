@@ -0,0 +1,150 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/howood/cryptotools/internal/entity"
+)
+
+func Test_Envelope(t *testing.T) {
+	envelope := &entity.Envelope{
+		Version:    1,
+		Algorithm:  entity.CipherAlgorithmAesGcm,
+		KeyID:      "key-2023",
+		Nonce:      []byte("123456789012"),
+		Ciphertext: []byte("ciphertext"),
+	}
+	data, err := MarshalEnvelope(envelope)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	checkdata := append([]byte{0xc7, 0x01, 0x01, 0x08}, "key-2023"...)
+	checkdata = append(checkdata, 0x00, 0x0c)
+	checkdata = append(checkdata, "123456789012ciphertext"...)
+	if reflect.DeepEqual(data, checkdata) == false {
+		t.Fatalf("failed compare MarshalEnvelope %v", data)
+	}
+	decoded, err := UnmarshalEnvelope(data)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if reflect.DeepEqual(decoded, envelope) == false {
+		t.Fatalf("failed compare UnmarshalEnvelope %#v", decoded)
+	}
+
+	data[1] = 0x09
+	var versionErr *UnsupportedEnvelopeVersionError
+	if _, err := UnmarshalEnvelope(data); !errors.As(err, &versionErr) || versionErr.Version != 0x09 {
+		t.Fatalf("failed UnmarshalEnvelope unknown version %#v", err)
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	for _, v := range [][]byte{nil, {0xc7}, {0x00, 0x01}, {0xc7, 0x01, 0x01, 0x05, 'a'}, {0xc7, 0x01, 0x01, 0x00, 0x03}} {
+		if _, err := UnmarshalEnvelope(v); !errors.Is(err, ErrInvalidEnvelope) {
+			t.Fatalf("failed UnmarshalEnvelope invalid data %v %#v", v, err)
+		}
+	}
+
+	envelope.Version = 0x09
+	if _, err := MarshalEnvelope(envelope); err == nil {
+		t.Fatal("failed MarshalEnvelope unknown version")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	t.Log("success Envelope")
+}
+
+func Test_EnvelopeWithKeyDerivation(t *testing.T) {
+	envelope := &entity.Envelope{
+		Version:   1,
+		Algorithm: entity.CipherAlgorithmXChaCha20Poly1305,
+		KeyDerivation: &entity.KeyDerivation{
+			Algorithm:   entity.KeyDerivationArgon2id,
+			Salt:        []byte("saltsaltsaltsalt"),
+			Iterations:  3,
+			Memory:      65536,
+			Parallelism: 4,
+		},
+		Nonce:      []byte("123456789012345678901234"),
+		Ciphertext: []byte("ciphertext"),
+	}
+	data, err := MarshalEnvelope(envelope)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	decoded, err := UnmarshalEnvelope(data)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if reflect.DeepEqual(decoded, envelope) == false {
+		t.Fatalf("failed compare UnmarshalEnvelope %#v", decoded)
+	}
+	if _, err := UnmarshalEnvelope(data[:25]); !errors.Is(err, ErrInvalidEnvelope) {
+		t.Fatalf("failed UnmarshalEnvelope truncated data %#v", err)
+	}
+
+	envelope.KeyDerivation = nil
+	data, err = MarshalEnvelope(envelope)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if decoded, err := UnmarshalEnvelope(data); err != nil || decoded.KeyDerivation != nil {
+		t.Fatalf("failed UnmarshalEnvelope without key derivation %#v", err)
+	}
+	t.Log("success EnvelopeWithKeyDerivation")
+}
+
+func Test_EnvelopeAdditionalData(t *testing.T) {
+	envelope := &entity.Envelope{
+		Version:   1,
+		Algorithm: entity.CipherAlgorithmAesGcm,
+		KeyID:     "key",
+		Nonce:     []byte("123456789012"),
+	}
+	data, err := MarshalEnvelope(envelope)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	additionalData, err := EnvelopeAdditionalData(envelope, []byte("record"))
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	header := data[:len(data)-len(envelope.Nonce)-1]
+	if reflect.DeepEqual(additionalData, append(header, "record"...)) == false {
+		t.Fatalf("failed compare EnvelopeAdditionalData %v", additionalData)
+	}
+	t.Log("success EnvelopeAdditionalData")
+}
+
+func Test_ReadEnvelopeHeader(t *testing.T) {
+	envelope := &entity.Envelope{
+		Version:   1,
+		Algorithm: entity.CipherAlgorithmAesGcm,
+		KeyID:     "key",
+		Nonce:     []byte("1234567"),
+	}
+	data, err := MarshalEnvelope(envelope)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	reader := bytes.NewReader(append(data, "chunks"...))
+	decoded, err := ReadEnvelopeHeader(reader)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if decoded.KeyID != "key" || reflect.DeepEqual(decoded.Nonce, envelope.Nonce) == false {
+		t.Fatalf("failed compare ReadEnvelopeHeader %#v", decoded)
+	}
+	rest, _ := io.ReadAll(reader)
+	if string(rest) != "chunks" {
+		t.Fatalf("failed ReadEnvelopeHeader rest %s", rest)
+	}
+	if _, err := ReadEnvelopeHeader(bytes.NewReader(data[:6])); !errors.Is(err, ErrInvalidEnvelope) {
+		t.Fatalf("failed ReadEnvelopeHeader truncated %#v", err)
+	}
+	t.Log("success ReadEnvelopeHeader")
+}
@@ -2,10 +2,14 @@ package parser
 
 import (
 	"crypto"
+	"crypto/ecdh"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/md5"
 	"crypto/rsa"
+	"encoding/base64"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 
@@ -13,6 +17,55 @@ import (
 	jose "gopkg.in/square/go-jose.v2"
 )
 
+const (
+	jwkKeyTypeOKP   = "OKP"
+	jwkCurveEd25519 = "Ed25519"
+	jwkCurveX25519  = "X25519"
+)
+
+const (
+	jwkUseSig = "sig"
+	jwkUseEnc = "enc"
+)
+
+// jwkAlgorithms is JWS / JWE alg defined in RFC 7518 and RFC 8037 and its use for each key type
+var jwkAlgorithms = map[entity.EncryptKeyType]map[string]string{
+	entity.EncryptTypeRSA: {
+		"RS256": jwkUseSig, "RS384": jwkUseSig, "RS512": jwkUseSig,
+		"PS256": jwkUseSig, "PS384": jwkUseSig, "PS512": jwkUseSig,
+		"RSA1_5": jwkUseEnc, "RSA-OAEP": jwkUseEnc, "RSA-OAEP-256": jwkUseEnc,
+	},
+	entity.EncryptTypeECDSA: {
+		"ES256": jwkUseSig, "ES384": jwkUseSig, "ES512": jwkUseSig,
+		"ECDH-ES": jwkUseEnc, "ECDH-ES+A128KW": jwkUseEnc, "ECDH-ES+A192KW": jwkUseEnc, "ECDH-ES+A256KW": jwkUseEnc,
+	},
+	entity.EncryptTypeED25519: {
+		"EdDSA": jwkUseSig,
+	},
+	entity.EncryptTypeX25519: {
+		"ECDH-ES": jwkUseEnc, "ECDH-ES+A128KW": jwkUseEnc, "ECDH-ES+A192KW": jwkUseEnc, "ECDH-ES+A256KW": jwkUseEnc,
+	},
+}
+
+// jwkKeyOps is key_ops defined in RFC 7517
+var jwkKeyOps = map[string]bool{
+	"sign": true, "verify": true, "encrypt": true, "decrypt": true,
+	"wrapKey": true, "unwrapKey": true, "deriveKey": true, "deriveBits": true,
+}
+
+// okpJSONWebKey is OKP JWK defined in RFC 8037. go-jose does not support X25519,
+// and does not check that d and x of Ed25519 keys match
+type okpJSONWebKey struct {
+	Use    string   `json:"use,omitempty"`
+	Kty    string   `json:"kty"`
+	Kid    string   `json:"kid,omitempty"`
+	Crv    string   `json:"crv"`
+	Alg    string   `json:"alg,omitempty"`
+	X      string   `json:"x"`
+	D      string   `json:"d,omitempty"`
+	KeyOps []string `json:"key_ops,omitempty"`
+}
+
 // ConvertToJSONWebKey convert to JWK
 func ConvertToJSONWebKey(input []byte) (jose.JSONWebKey, error) {
 	var jwk jose.JSONWebKey
@@ -20,16 +73,142 @@ func ConvertToJSONWebKey(input []byte) (jose.JSONWebKey, error) {
 	return jwk, err
 }
 
+// DecodeJWK decodes RSA / EC / OKP (Ed25519, X25519) JWK to entity struct.
+// The key type is taken from kty and crv, and the private key is set when d is present.
+func DecodeJWK(input []byte, encryptkey *entity.EncryptKey) error {
+	var header struct {
+		Kty string `json:"kty"`
+		Crv string `json:"crv"`
+	}
+	if err := json.Unmarshal(input, &header); err != nil {
+		return err
+	}
+	if header.Kty == jwkKeyTypeOKP && header.Crv == jwkCurveX25519 {
+		return decodeX25519JWK(input, encryptkey)
+	}
+	if header.Kty == jwkKeyTypeOKP && header.Crv == jwkCurveEd25519 {
+		return decodeEd25519JWK(input, encryptkey)
+	}
+	jwk, err := ConvertToJSONWebKey(input)
+	if err != nil {
+		return err
+	}
+	if jwk.IsPublic() {
+		return castPublicKeyToEncryptKey(jwk.Key, encryptkey)
+	}
+	if err := checkJWKPrivateKey(jwk.Key); err != nil {
+		return err
+	}
+	return castPrivateKeyToEncryptKey(jwk.Key, encryptkey)
+}
+
+// checkJWKPrivateKey checks that d of RSA / EC JWK matches the public key, which go-jose does not check
+func checkJWKPrivateKey(key interface{}) error {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		if err := k.Validate(); err != nil {
+			return fmt.Errorf("RSA JWK public key does not match private key : %w", err)
+		}
+	case *ecdsa.PrivateKey:
+		if k.D.Sign() <= 0 || k.D.Cmp(k.Curve.Params().N) >= 0 {
+			return errors.New("invalid EC JWK private key")
+		}
+		x, y := k.Curve.ScalarBaseMult(k.D.Bytes())
+		if x.Cmp(k.X) != 0 || y.Cmp(k.Y) != 0 {
+			return errors.New("EC JWK public key does not match private key")
+		}
+	}
+	return nil
+}
+
+func decodeX25519JWK(input []byte, encryptkey *entity.EncryptKey) error {
+	var jwk okpJSONWebKey
+	if err := json.Unmarshal(input, &jwk); err != nil {
+		return err
+	}
+	x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+	if err != nil {
+		return err
+	}
+	publickey, err := ecdh.X25519().NewPublicKey(x)
+	if err != nil {
+		return err
+	}
+	if jwk.D != "" {
+		d, err := base64.RawURLEncoding.DecodeString(jwk.D)
+		if err != nil {
+			return err
+		}
+		privatekey, err := ecdh.X25519().NewPrivateKey(d)
+		if err != nil {
+			return err
+		}
+		if !privatekey.PublicKey().Equal(publickey) {
+			return errors.New("X25519 JWK public key does not match private key")
+		}
+		encryptkey.X25519Key.PrivateKey = privatekey
+	}
+	encryptkey.X25519Key.PublicKey = publickey
+	encryptkey.Keytype = entity.EncryptTypeX25519
+	return nil
+}
+
+func decodeEd25519JWK(input []byte, encryptkey *entity.EncryptKey) error {
+	var jwk okpJSONWebKey
+	if err := json.Unmarshal(input, &jwk); err != nil {
+		return err
+	}
+	x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+	if err != nil {
+		return err
+	}
+	if len(x) != ed25519.PublicKeySize {
+		return errors.New("Invalid Ed25519 JWK public key size")
+	}
+	publickey := ed25519.PublicKey(x)
+	if jwk.D != "" {
+		d, err := base64.RawURLEncoding.DecodeString(jwk.D)
+		if err != nil {
+			return err
+		}
+		if len(d) != ed25519.SeedSize {
+			return errors.New("Invalid Ed25519 JWK private key size")
+		}
+		privatekey := ed25519.NewKeyFromSeed(d)
+		if !publickey.Equal(privatekey.Public()) {
+			return errors.New("Ed25519 JWK public key does not match private key")
+		}
+		encryptkey.Ed25519Key.PrivateKey = &privatekey
+	}
+	encryptkey.Ed25519Key.PublicKey = &publickey
+	encryptkey.Keytype = entity.EncryptTypeED25519
+	return nil
+}
+
 // GenerateJSONWebKeyWithEncryptPrivateKey convert  privatekey to JWK
 func GenerateJSONWebKeyWithEncryptPrivateKey(encryptkey *entity.EncryptKey, kid string) ([]byte, error) {
+	return GenerateJSONWebKeyWithEncryptPrivateKeyOptions(encryptkey, entity.JWKOptions{KeyID: kid})
+}
+
+// GenerateJSONWebKeyWithEncryptPrivateKeyOptions convert privatekey to JWK with kid, alg, use and key_ops
+func GenerateJSONWebKeyWithEncryptPrivateKeyOptions(encryptkey *entity.EncryptKey, options entity.JWKOptions) ([]byte, error) {
+	var key interface{}
 	switch encryptkey.Keytype {
 	case entity.EncryptTypeRSA:
-		return GenerateJSONWebKeyWithRSAPrivateKey(encryptkey.RsaKey.PrivateKey, kid)
+		key = encryptkey.RsaKey.PrivateKey
 	case entity.EncryptTypeECDSA:
-		return GenerateJSONWebKeyWithEcdsaPrivateKey(encryptkey.EcdsaKey.PrivateKey, kid)
+		key = encryptkey.EcdsaKey.PrivateKey
+	case entity.EncryptTypeED25519:
+		key = *encryptkey.Ed25519Key.PrivateKey
+	case entity.EncryptTypeX25519:
+		key = encryptkey.X25519Key.PrivateKey
 	default:
 		return nil, errors.New("No encryptkey KeyType")
 	}
+	if err := validateJWKOptions(encryptkey, options); err != nil {
+		return nil, err
+	}
+	return marshalJSONWebKey(key, options)
 }
 
 // GenerateJSONWebKeyWithRSAPrivateKey convert rsa privatekey to JWK
@@ -50,24 +229,59 @@ func GenerateJSONWebKeyWithEcdsaPrivateKey(privatekey *ecdsa.PrivateKey, kid str
 	return jwk.MarshalJSON()
 }
 
-// GenerateJSONWebKeyWithEncryptPublicKey convert  publickey to JWK
+// GenerateJSONWebKeyWithEd25519PrivateKey convert ed25519 privatekey to JWK
+func GenerateJSONWebKeyWithEd25519PrivateKey(privatekey *ed25519.PrivateKey, kid string) ([]byte, error) {
+	return marshalJSONWebKey(*privatekey, entity.JWKOptions{KeyID: kid})
+}
+
+// GenerateJSONWebKeyWithX25519PrivateKey convert x25519 privatekey to JWK
+func GenerateJSONWebKeyWithX25519PrivateKey(privatekey *ecdh.PrivateKey, kid string) ([]byte, error) {
+	return marshalJSONWebKey(privatekey, entity.JWKOptions{KeyID: kid})
+}
+
+// GenerateJSONWebKeyWithEncryptPublicKey convert  publickey to JWK.
+// alg is set only for key types whose algorithm is determined by the key, and not for RSA.
 func GenerateJSONWebKeyWithEncryptPublicKey(encryptkey *entity.EncryptKey, kid string) ([]byte, error) {
+	options := entity.JWKOptions{KeyID: kid}
+	switch encryptkey.Keytype {
+	case entity.EncryptTypeECDSA:
+		options.Algorithm = getPublickeyAlgorithm(encryptkey.EcdsaKey.PublicKey)
+	case entity.EncryptTypeED25519:
+		options.Algorithm = getPublickeyAlgorithm(*encryptkey.Ed25519Key.PublicKey)
+	case entity.EncryptTypeX25519:
+		options.Algorithm = getPublickeyAlgorithm(encryptkey.X25519Key.PublicKey)
+	}
+	return GenerateJSONWebKeyWithEncryptPublicKeyOptions(encryptkey, options)
+}
+
+// GenerateJSONWebKeyWithEncryptPublicKeyOptions convert publickey to JWK with kid, alg, use and key_ops.
+// alg is set only when Algorithm is given.
+func GenerateJSONWebKeyWithEncryptPublicKeyOptions(encryptkey *entity.EncryptKey, options entity.JWKOptions) ([]byte, error) {
+	var key interface{}
 	switch encryptkey.Keytype {
 	case entity.EncryptTypeRSA:
-		return GenerateJSONWebKeyWithRSAPublicKey(encryptkey.RsaKey.PublicKey, kid)
+		key = encryptkey.RsaKey.PublicKey
 	case entity.EncryptTypeECDSA:
-		return GenerateJSONWebKeyWithEcdsaPublicKey(encryptkey.EcdsaKey.PublicKey, kid)
+		key = encryptkey.EcdsaKey.PublicKey
+	case entity.EncryptTypeED25519:
+		key = *encryptkey.Ed25519Key.PublicKey
+	case entity.EncryptTypeX25519:
+		key = encryptkey.X25519Key.PublicKey
 	default:
 		return nil, errors.New("No encryptkey KeyType")
 	}
+	if err := validateJWKOptions(encryptkey, options); err != nil {
+		return nil, err
+	}
+	return marshalJSONWebKey(key, options)
 }
 
-// GenerateJSONWebKeyWithRSAPublicKey convert rsa publickey to JWK
+// GenerateJSONWebKeyWithRSAPublicKey convert rsa publickey to JWK without alg,
+// as RSA keys are used with several signature and encryption algorithms
 func GenerateJSONWebKeyWithRSAPublicKey(publickey *rsa.PublicKey, kid string) ([]byte, error) {
 	jwk := jose.JSONWebKey{
-		KeyID:     kid,
-		Key:       publickey,
-		Algorithm: getPublickeyAlgorithm(publickey),
+		KeyID: kid,
+		Key:   publickey,
 	}
 	return jwk.MarshalJSON()
 }
@@ -82,6 +296,86 @@ func GenerateJSONWebKeyWithEcdsaPublicKey(publickey *ecdsa.PublicKey, kid string
 	return jwk.MarshalJSON()
 }
 
+// GenerateJSONWebKeyWithEd25519PublicKey convert ed25519 publickey to JWK
+func GenerateJSONWebKeyWithEd25519PublicKey(publickey *ed25519.PublicKey, kid string) ([]byte, error) {
+	return marshalJSONWebKey(*publickey, entity.JWKOptions{KeyID: kid, Algorithm: getPublickeyAlgorithm(*publickey)})
+}
+
+// GenerateJSONWebKeyWithX25519PublicKey convert x25519 publickey to JWK
+func GenerateJSONWebKeyWithX25519PublicKey(publickey *ecdh.PublicKey, kid string) ([]byte, error) {
+	return marshalJSONWebKey(publickey, entity.JWKOptions{KeyID: kid, Algorithm: getPublickeyAlgorithm(publickey)})
+}
+
+// marshalJSONWebKey marshals key to JWK. key_ops is added to the go-jose output as go-jose does not support it
+func marshalJSONWebKey(key interface{}, options entity.JWKOptions) ([]byte, error) {
+	switch key := key.(type) {
+	case *ecdh.PrivateKey:
+		return json.Marshal(okpJSONWebKey{
+			Use:    options.Use,
+			Kty:    jwkKeyTypeOKP,
+			Kid:    options.KeyID,
+			Crv:    jwkCurveX25519,
+			Alg:    options.Algorithm,
+			X:      base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+			D:      base64.RawURLEncoding.EncodeToString(key.Bytes()),
+			KeyOps: options.KeyOps,
+		})
+	case *ecdh.PublicKey:
+		return json.Marshal(okpJSONWebKey{
+			Use:    options.Use,
+			Kty:    jwkKeyTypeOKP,
+			Kid:    options.KeyID,
+			Crv:    jwkCurveX25519,
+			Alg:    options.Algorithm,
+			X:      base64.RawURLEncoding.EncodeToString(key.Bytes()),
+			KeyOps: options.KeyOps,
+		})
+	}
+	jwk := jose.JSONWebKey{
+		KeyID:     options.KeyID,
+		Key:       key,
+		Algorithm: options.Algorithm,
+		Use:       options.Use,
+	}
+	jwkbyte, err := jwk.MarshalJSON()
+	if err != nil || len(options.KeyOps) == 0 {
+		return jwkbyte, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(jwkbyte, &fields); err != nil {
+		return nil, err
+	}
+	fields["key_ops"] = options.KeyOps
+	return json.Marshal(fields)
+}
+
+// validateJWKOptions checks that alg, use and key_ops are valid for the key type
+func validateJWKOptions(encryptkey *entity.EncryptKey, options entity.JWKOptions) error {
+	if options.Use != "" && options.Use != jwkUseSig && options.Use != jwkUseEnc {
+		return fmt.Errorf("invalid JWK use : %s", options.Use)
+	}
+	if options.Algorithm != "" {
+		use, ok := jwkAlgorithms[encryptkey.Keytype][options.Algorithm]
+		if !ok {
+			return fmt.Errorf("invalid JWK alg for %s key : %s", encryptkey.Keytype, options.Algorithm)
+		}
+		if encryptkey.Keytype == entity.EncryptTypeECDSA && use == jwkUseSig && options.Algorithm != getPublickeyAlgorithm(encryptkey.EcdsaKey.PublicKey) {
+			return fmt.Errorf("invalid JWK alg for the curve : %s", options.Algorithm)
+		}
+		if options.Use != "" && options.Use != use {
+			return fmt.Errorf("JWK alg %s can not be used for %s", options.Algorithm, options.Use)
+		}
+	}
+	seen := make(map[string]bool, len(options.KeyOps))
+	for _, keyop := range options.KeyOps {
+		if !jwkKeyOps[keyop] || seen[keyop] {
+			return fmt.Errorf("invalid JWK key_ops : %s", keyop)
+		}
+		seen[keyop] = true
+	}
+	return nil
+}
+
 // ConvertToRSAPublicFromJWK convert to RSA public key from JWK
 func ConvertToRSAPublicFromJWK(key *jose.JSONWebKey) (*rsa.PublicKey, error) {
 	res, ok := key.Key.(*rsa.PublicKey)
@@ -109,7 +403,42 @@ func ConvertToEcdsaPublicFromJWK(key *jose.JSONWebKey) (*ecdsa.PublicKey, error)
 	return res, nil
 }
 
-// GenerateHashFromCrptoKey generates Hash from private / public key
+// GenerateJWKThumbprint generates RFC 7638 JWK thumbprint of publickey encoded with base64url
+func GenerateJWKThumbprint(encryptkey *entity.EncryptKey, hash crypto.Hash) (string, error) {
+	if !hash.Available() {
+		return "", errors.New("hash function is not available")
+	}
+	var thumbprint []byte
+	switch encryptkey.Keytype {
+	case entity.EncryptTypeRSA, entity.EncryptTypeECDSA, entity.EncryptTypeED25519:
+		var key interface{}
+		switch encryptkey.Keytype {
+		case entity.EncryptTypeRSA:
+			key = encryptkey.RsaKey.PublicKey
+		case entity.EncryptTypeECDSA:
+			key = encryptkey.EcdsaKey.PublicKey
+		case entity.EncryptTypeED25519:
+			key = *encryptkey.Ed25519Key.PublicKey
+		}
+		jwk := jose.JSONWebKey{Key: key}
+		var err error
+		if thumbprint, err = jwk.Thumbprint(hash); err != nil {
+			return "", err
+		}
+	case entity.EncryptTypeX25519:
+		// required members in lexicographic order as in RFC 7638 and RFC 8037
+		input := fmt.Sprintf(`{"crv":"%s","kty":"%s","x":"%s"}`, jwkCurveX25519, jwkKeyTypeOKP, base64.RawURLEncoding.EncodeToString(encryptkey.X25519Key.PublicKey.Bytes()))
+		hasher := hash.New()
+		hasher.Write([]byte(input))
+		thumbprint = hasher.Sum(nil)
+	default:
+		return "", errors.New("No encryptkey KeyType")
+	}
+	return base64.RawURLEncoding.EncodeToString(thumbprint), nil
+}
+
+// GenerateHashFromCrptoKey generates Hash from private / public key.
+// It is MD5 of the formatted key kept for key IDs of older versions; use GenerateJWKThumbprint instead.
 func GenerateHashFromCrptoKey(key interface{}) string {
 	hasher := md5.New()
 	hasher.Write([]byte(fmt.Sprintf("%v", key)))
@@ -118,8 +447,10 @@ func GenerateHashFromCrptoKey(key interface{}) string {
 
 func getPublickeyAlgorithm(pub crypto.PublicKey) string {
 	switch pub := pub.(type) {
-	case *rsa.PublicKey:
-		return "RS256"
+	case ed25519.PublicKey:
+		return "EdDSA"
+	case *ecdh.PublicKey:
+		return "ECDH-ES"
 	case *ecdsa.PublicKey:
 		switch pub.Params().Name {
 		case "P-256":
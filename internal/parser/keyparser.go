@@ -0,0 +1,173 @@
+package parser
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/howood/cryptotools/internal/entity"
+	"golang.org/x/crypto/ssh"
+)
+
+const errorUnknownKeyFormat = "unrecognized key format, PEM, DER, JWK, JWKS, OpenSSH private key or authorized_keys line is expected"
+
+// DetectKeyFormat detects the format of key data
+func DetectKeyFormat(input []byte) (entity.KeyFormat, error) {
+	data := bytes.TrimSpace(input)
+	switch {
+	case bytes.HasPrefix(data, []byte("-----BEGIN ")):
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return "", errors.New("failed to decode PEM block")
+		}
+		if block.Type == blockTypeOpenSSHPrivateKey {
+			return entity.KeyFormatOpenSSH, nil
+		}
+		return entity.KeyFormatPEM, nil
+	case bytes.HasPrefix(data, []byte("{")):
+		var jwks struct {
+			Keys json.RawMessage `json:"keys"`
+		}
+		if err := json.Unmarshal(data, &jwks); err != nil {
+			return "", fmt.Errorf("failed to decode JSON key : %w", err)
+		}
+		if jwks.Keys != nil {
+			return entity.KeyFormatJWKS, nil
+		}
+		return entity.KeyFormatJWK, nil
+	case bytes.HasPrefix(data, []byte(opensshMagic)):
+		return entity.KeyFormatOpenSSH, nil
+	case len(input) > 0 && input[0] == 0x30:
+		// DER is ASN.1 SEQUENCE. Binary DER is never trimmed, so it must start at the first byte
+		return entity.KeyFormatDER, nil
+	}
+	if _, _, _, _, err := ssh.ParseAuthorizedKey(data); err == nil {
+		return entity.KeyFormatAuthorizedKey, nil
+	}
+	return "", errors.New(errorUnknownKeyFormat)
+}
+
+// DecodeKey decodes key data of any supported format to entity struct and returns the detected format.
+// Encrypted private key is decrypted with passphrase, and the key in JWKS is selected with kid.
+func DecodeKey(input, passphrase []byte, kid string, encryptkey *entity.EncryptKey) (entity.KeyFormat, error) {
+	format, err := DetectKeyFormat(input)
+	if err != nil {
+		return "", err
+	}
+	data := bytes.TrimSpace(input)
+	switch format {
+	case entity.KeyFormatPEM, entity.KeyFormatOpenSSH:
+		if !bytes.HasPrefix(data, []byte("-----BEGIN ")) {
+			// only the leading space is trimmed since the trailing padding of binary key may be space
+			key, comment, err := ParseOpenSSHPrivateKey(bytes.TrimLeftFunc(input, unicode.IsSpace), passphrase)
+			if err != nil {
+				return "", err
+			}
+			encryptkey.Comment = comment
+			return format, castPrivateKeyToEncryptKey(key, encryptkey)
+		}
+		block, _ := pem.Decode(data)
+		switch {
+		case strings.HasSuffix(block.Type, "PRIVATE KEY"):
+			if len(passphrase) > 0 {
+				return format, DecodePrivateKeyWithPassphrase(data, passphrase, encryptkey)
+			}
+			return format, DecodePrivateKey(data, encryptkey)
+		case strings.HasSuffix(block.Type, "PUBLIC KEY"):
+			return format, DecodePublicKey(data, encryptkey)
+		default:
+			return "", fmt.Errorf("unsupported PEM block type : %s", block.Type)
+		}
+	case entity.KeyFormatJWK:
+		return format, DecodeJWK(data, encryptkey)
+	case entity.KeyFormatJWKS:
+		return format, DecodeJWKS(data, kid, encryptkey)
+	case entity.KeyFormatDER:
+		return format, DecodeDERKey(input, passphrase, encryptkey)
+	default:
+		return format, DecodeAuthorizedKey(data, encryptkey)
+	}
+}
+
+// DecodeJWKS decodes the key with kid in JWKS to entity struct.
+// When kid is empty, JWKS must have only one key.
+func DecodeJWKS(input []byte, kid string, encryptkey *entity.EncryptKey) error {
+	var jwks struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	if err := json.Unmarshal(input, &jwks); err != nil {
+		return err
+	}
+	if kid == "" {
+		if len(jwks.Keys) != 1 {
+			return fmt.Errorf("JWKS has %d keys, key ID is required to select the key", len(jwks.Keys))
+		}
+		return DecodeJWK(jwks.Keys[0], encryptkey)
+	}
+	for _, key := range jwks.Keys {
+		var header struct {
+			Kid string `json:"kid"`
+		}
+		if err := json.Unmarshal(key, &header); err != nil {
+			return err
+		}
+		if header.Kid == kid {
+			return DecodeJWK(key, encryptkey)
+		}
+	}
+	return fmt.Errorf("no key with kid %s in JWKS", kid)
+}
+
+// DecodeDERKey decodes DER encoded key to entity struct.
+// PKCS1, SEC1, PKCS8 private key, PKIX and PKCS1 public key, and PKCS8 encrypted private key with passphrase are supported.
+func DecodeDERKey(der, passphrase []byte, encryptkey *entity.EncryptKey) error {
+	if isEncryptedPKCS8PrivateKey(der) {
+		if len(passphrase) == 0 {
+			return errors.New("private key is encrypted, passphrase is required")
+		}
+		decrypted, err := DecryptPKCS8PrivateKey(der, passphrase)
+		if err != nil {
+			return err
+		}
+		der = decrypted
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		return castPrivateKeyToEncryptKey(key, encryptkey)
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return castPrivateKeyToEncryptKey(key, encryptkey)
+	}
+	if key, err := x509.ParseECPrivateKey(der); err == nil {
+		return castPrivateKeyToEncryptKey(key, encryptkey)
+	}
+	if key, err := x509.ParsePKIXPublicKey(der); err == nil {
+		return castPublicKeyToEncryptKey(key, encryptkey)
+	}
+	if key, err := x509.ParsePKCS1PublicKey(der); err == nil {
+		return castPublicKeyToEncryptKey(key, encryptkey)
+	}
+	return errors.New("failed to decode DER key, PKCS1, SEC1, PKCS8 or PKIX key is expected")
+}
+
+// EncodeJWKS encodes JWKs to JWKS
+func EncodeJWKS(jwks ...[]byte) ([]byte, error) {
+	keys := make([]json.RawMessage, 0, len(jwks))
+	for _, jwk := range jwks {
+		keys = append(keys, json.RawMessage(jwk))
+	}
+	return json.Marshal(struct {
+		Keys []json.RawMessage `json:"keys"`
+	}{Keys: keys})
+}
+
+func isEncryptedPKCS8PrivateKey(der []byte) bool {
+	var info encryptedPrivateKeyInfo
+	rest, err := asn1.Unmarshal(der, &info)
+	return err == nil && len(rest) == 0 && info.EncryptionAlgorithm.Algorithm.Equal(oidPBES2)
+}
@@ -0,0 +1,123 @@
+package parser
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"testing"
+
+	"github.com/howood/cryptotools/internal/entity"
+)
+
+func Test_DecodeKey(t *testing.T) {
+	rsaPrivateKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	ecdsaPrivateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	ed25519PublicKey, ed25519PrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	pkcs8der, err := x509.MarshalPKCS8PrivateKey(ed25519PrivateKey)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	sec1der, err := x509.MarshalECPrivateKey(ecdsaPrivateKey)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	pkixder, err := x509.MarshalPKIXPublicKey(ed25519PublicKey)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	encryptedder, err := EncryptPKCS8PrivateKey(pkcs8der, []byte("abc"), entity.PrivateKeyEncryption{KDF: entity.PrivateKeyKDFPBKDF2SHA256})
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	rsakey := &entity.EncryptKey{}
+	if err := castPrivateKeyToEncryptKey(rsaPrivateKey, rsakey); err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	rsakey.RsaKey.PublicKey = &rsaPrivateKey.PublicKey
+	rsajwk, err := GenerateJSONWebKeyWithEncryptPublicKey(rsakey, "rsakey")
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	jwks := fmt.Sprintf(`{"keys":[%s,{"kty":"OKP","crv":"X25519","kid":"x25519key","x":"hSDwCYkwp1R0i33ctD73Wg2_Og0mOBr066SpjqqbTmo"}]}`, rsajwk)
+
+	testcases := []struct {
+		name       string
+		data       []byte
+		passphrase []byte
+		kid        string
+		format     entity.KeyFormat
+		keytype    entity.EncryptKeyType
+		private    bool
+	}{
+		{"pkcs1 pem", EncodeRsaPrivateKeyPKCS1(rsaPrivateKey), nil, "", entity.KeyFormatPEM, entity.EncryptTypeRSA, true},
+		{"pkix pem", []byte(pkcs8PublicKey), nil, "", entity.KeyFormatPEM, entity.EncryptTypeECDSA, false},
+		{"encrypted pem", []byte(pkcs8ScryptPrivateKey), []byte("abc"), "", entity.KeyFormatPEM, entity.EncryptTypeECDSA, true},
+		{"pkcs1 der", x509.MarshalPKCS1PrivateKey(rsaPrivateKey), nil, "", entity.KeyFormatDER, entity.EncryptTypeRSA, true},
+		{"pkcs1 public der", x509.MarshalPKCS1PublicKey(&rsaPrivateKey.PublicKey), nil, "", entity.KeyFormatDER, entity.EncryptTypeRSA, false},
+		{"sec1 der", sec1der, nil, "", entity.KeyFormatDER, entity.EncryptTypeECDSA, true},
+		{"pkcs8 der", pkcs8der, nil, "", entity.KeyFormatDER, entity.EncryptTypeED25519, true},
+		{"pkix der", pkixder, nil, "", entity.KeyFormatDER, entity.EncryptTypeED25519, false},
+		{"encrypted der", encryptedder, []byte("abc"), "", entity.KeyFormatDER, entity.EncryptTypeED25519, true},
+		{"jwk", rsajwk, nil, "", entity.KeyFormatJWK, entity.EncryptTypeRSA, false},
+		{"jwks", []byte(jwks), nil, "x25519key", entity.KeyFormatJWKS, entity.EncryptTypeX25519, false},
+		{"openssh", []byte(opensshEd25519PrivateKeyEncrypted), []byte("abc"), "", entity.KeyFormatOpenSSH, entity.EncryptTypeED25519, true},
+		{"openssh raw", MarshalED25519PrivateKey(&ed25519PrivateKey), nil, "", entity.KeyFormatOpenSSH, entity.EncryptTypeED25519, true},
+		{"openssh raw with leading space", append([]byte("\n "), MarshalED25519PrivateKey(&ed25519PrivateKey)...), nil, "", entity.KeyFormatOpenSSH, entity.EncryptTypeED25519, true},
+		{"authorized key", []byte("  " + opensshRsaPrivateKeyAuthorized + "\n"), nil, "", entity.KeyFormatAuthorizedKey, entity.EncryptTypeRSA, false},
+	}
+
+	for _, tc := range testcases {
+		encryptkey := &entity.EncryptKey{}
+		format, err := DecodeKey(tc.data, tc.passphrase, tc.kid, encryptkey)
+		if err != nil {
+			t.Fatalf("failed test :%s %#v", tc.name, err)
+		}
+		if format != tc.format || encryptkey.Keytype != tc.keytype {
+			t.Fatalf("failed DecodeKey :%s %s %s", tc.name, format, encryptkey.Keytype)
+		}
+		private := encryptkey.RsaKey.PrivateKey != nil || encryptkey.EcdsaKey.PrivateKey != nil || encryptkey.Ed25519Key.PrivateKey != nil || encryptkey.X25519Key.PrivateKey != nil
+		if private != tc.private {
+			t.Fatalf("failed DecodeKey private key :%s", tc.name)
+		}
+	}
+
+	errorcases := []struct {
+		name       string
+		data       []byte
+		passphrase []byte
+		kid        string
+	}{
+		{"unknown text", []byte("not a key"), nil, ""},
+		{"empty", []byte{}, nil, ""},
+		{"broken der", []byte{0x30, 0x03, 0x02, 0x01}, nil, ""},
+		{"der with leading space", append([]byte(" "), pkcs8der...), nil, ""},
+		{"encrypted der without passphrase", encryptedder, nil, ""},
+		{"encrypted pem without passphrase", []byte(pkcs8ScryptPrivateKey), nil, ""},
+		{"jwks without kid", []byte(jwks), nil, ""},
+		{"jwks with unknown kid", []byte(jwks), nil, "unknown"},
+		{"broken json", []byte("{"), nil, ""},
+		{"unsupported pem", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: []byte{0x30}}), nil, ""},
+	}
+	for _, tc := range errorcases {
+		if _, err := DecodeKey(tc.data, tc.passphrase, tc.kid, &entity.EncryptKey{}); err == nil {
+			t.Fatalf("failed DecodeKey :%s", tc.name)
+		} else {
+			t.Logf("failed test :%s %#v", tc.name, err)
+		}
+	}
+	t.Log("success DecodeKey")
+}
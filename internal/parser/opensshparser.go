@@ -0,0 +1,435 @@
+package parser
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/dchest/bcrypt_pbkdf"
+	"github.com/howood/cryptotools/internal/entity"
+	"golang.org/x/crypto/ssh"
+)
+
+// openssh-key-v1 format is described in PROTOCOL.key of OpenSSH
+const (
+	opensshMagic           = "openssh-key-v1\x00"
+	opensshCipherNone      = "none"
+	opensshCipherAes256Ctr = "aes256-ctr"
+	opensshKdfNone         = "none"
+	opensshKdfBcrypt       = "bcrypt"
+	opensshSaltSize        = 16
+	opensshRounds          = 16
+	opensshMaxRounds       = 1 << 12
+	opensshMaxSaltSize     = 64
+	opensshMaxRsaBits      = 16384
+	opensshBlockSize       = 8
+)
+
+type opensshKeyFile struct {
+	CipherName   string
+	KdfName      string
+	KdfOpts      string
+	NumKeys      uint32
+	PubKey       []byte
+	PrivKeyBlock []byte
+}
+
+type opensshBcryptOptions struct {
+	Salt   []byte
+	Rounds uint32
+}
+
+type opensshPrivateKeyHeader struct {
+	Check1  uint32
+	Check2  uint32
+	Keytype string
+	Rest    []byte `ssh:"rest"`
+}
+
+type opensshRsaPrivateKey struct {
+	N       *big.Int
+	E       *big.Int
+	D       *big.Int
+	Iqmp    *big.Int
+	P       *big.Int
+	Q       *big.Int
+	Comment string
+	Pad     []byte `ssh:"rest"`
+}
+
+type opensshEcdsaPrivateKey struct {
+	Curve   string
+	Pub     []byte
+	D       *big.Int
+	Comment string
+	Pad     []byte `ssh:"rest"`
+}
+
+type opensshEd25519PrivateKey struct {
+	Pub     []byte
+	Priv    []byte
+	Comment string
+	Pad     []byte `ssh:"rest"`
+}
+
+// opensshCipher is cipher used to encrypt OpenSSH private key
+type opensshCipher struct {
+	keySize   int
+	blockSize int
+	stream    func(block cipher.Block, iv []byte) cipher.Stream
+}
+
+var opensshCiphers = map[string]opensshCipher{
+	"aes128-ctr":           {keySize: 16, blockSize: aes.BlockSize, stream: cipher.NewCTR},
+	"aes192-ctr":           {keySize: 24, blockSize: aes.BlockSize, stream: cipher.NewCTR},
+	opensshCipherAes256Ctr: {keySize: 32, blockSize: aes.BlockSize, stream: cipher.NewCTR},
+}
+
+var opensshCurveNames = map[elliptic.Curve]string{
+	elliptic.P256(): "nistp256",
+	elliptic.P384(): "nistp384",
+	elliptic.P521(): "nistp521",
+}
+
+// DecodeOpenSSHPrivateKey decodes OpenSSH private key to entity struct with the comment.
+// Private key encrypted with bcrypt-pbkdf and aes-ctr is decrypted with passphrase.
+func DecodeOpenSSHPrivateKey(bytedata, passphrase []byte, encryptkey *entity.EncryptKey) error {
+	block, _ := pem.Decode(bytedata)
+	if block == nil {
+		return errors.New("failed to decode private key data")
+	}
+	if block.Type != blockTypeOpenSSHPrivateKey {
+		return fmt.Errorf("invalid private key type : %s", block.Type)
+	}
+	key, comment, err := ParseOpenSSHPrivateKey(block.Bytes, passphrase)
+	if err != nil {
+		return err
+	}
+	if err := castPrivateKeyToEncryptKey(key, encryptkey); err != nil {
+		return err
+	}
+	encryptkey.Comment = comment
+	return nil
+}
+
+// ParseOpenSSHPrivateKey parses openssh-key-v1 private key and returns the key and the comment
+func ParseOpenSSHPrivateKey(der, passphrase []byte) (interface{}, string, error) {
+	if !bytes.HasPrefix(der, []byte(opensshMagic)) {
+		return nil, "", errors.New("invalid openssh private key format")
+	}
+	var w opensshKeyFile
+	if err := ssh.Unmarshal(der[len(opensshMagic):], &w); err != nil {
+		return nil, "", err
+	}
+	if w.NumKeys != 1 {
+		return nil, "", errors.New("multiple keys in openssh private key are not supported")
+	}
+
+	privkeyblock, blocksize, err := decryptOpenSSHPrivateKeyBlock(&w, passphrase)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var pk1 opensshPrivateKeyHeader
+	if err := ssh.Unmarshal(privkeyblock, &pk1); err != nil || pk1.Check1 != pk1.Check2 {
+		if w.CipherName != opensshCipherNone {
+			return nil, "", errors.New("failed to decrypt openssh private key, passphrase may be incorrect")
+		}
+		return nil, "", errors.New("invalid openssh private key format")
+	}
+
+	var key, pubkey interface{}
+	var comment string
+	switch pk1.Keytype {
+	case ssh.KeyAlgoRSA:
+		var k opensshRsaPrivateKey
+		if err := ssh.Unmarshal(pk1.Rest, &k); err != nil {
+			return nil, "", err
+		}
+		if err := checkOpenSSHPadding(k.Pad, blocksize); err != nil {
+			return nil, "", err
+		}
+		if k.N.BitLen() > opensshMaxRsaBits {
+			return nil, "", errors.New("rsa private key is too large")
+		}
+		if k.E.BitLen() > 31 {
+			return nil, "", errors.New("invalid rsa public exponent")
+		}
+		rsakey := &rsa.PrivateKey{
+			PublicKey: rsa.PublicKey{N: k.N, E: int(k.E.Int64())},
+			D:         k.D,
+			Primes:    []*big.Int{k.P, k.Q},
+		}
+		if err := rsakey.Validate(); err != nil {
+			return nil, "", err
+		}
+		rsakey.Precompute()
+		key, pubkey, comment = rsakey, &rsakey.PublicKey, k.Comment
+	case ssh.KeyAlgoECDSA256, ssh.KeyAlgoECDSA384, ssh.KeyAlgoECDSA521:
+		var k opensshEcdsaPrivateKey
+		if err := ssh.Unmarshal(pk1.Rest, &k); err != nil {
+			return nil, "", err
+		}
+		if err := checkOpenSSHPadding(k.Pad, blocksize); err != nil {
+			return nil, "", err
+		}
+		var curve elliptic.Curve
+		for c, name := range opensshCurveNames {
+			if name == k.Curve && pk1.Keytype == "ecdsa-sha2-"+name {
+				curve = c
+			}
+		}
+		if curve == nil {
+			return nil, "", fmt.Errorf("unsupported ecdsa curve : %s", k.Curve)
+		}
+		x, y := elliptic.Unmarshal(curve, k.Pub)
+		if x == nil {
+			return nil, "", errors.New("invalid ecdsa public key")
+		}
+		if k.D.Sign() <= 0 || k.D.Cmp(curve.Params().N) >= 0 {
+			return nil, "", errors.New("invalid ecdsa private key")
+		}
+		dx, dy := curve.ScalarBaseMult(k.D.Bytes())
+		if dx.Cmp(x) != 0 || dy.Cmp(y) != 0 {
+			return nil, "", errors.New("ecdsa private key does not match public key")
+		}
+		ecdsakey := &ecdsa.PrivateKey{
+			PublicKey: ecdsa.PublicKey{Curve: curve, X: x, Y: y},
+			D:         k.D,
+		}
+		key, pubkey, comment = ecdsakey, &ecdsakey.PublicKey, k.Comment
+	case ssh.KeyAlgoED25519:
+		var k opensshEd25519PrivateKey
+		if err := ssh.Unmarshal(pk1.Rest, &k); err != nil {
+			return nil, "", err
+		}
+		if err := checkOpenSSHPadding(k.Pad, blocksize); err != nil {
+			return nil, "", err
+		}
+		if len(k.Priv) != ed25519.PrivateKeySize || len(k.Pub) != ed25519.PublicKeySize {
+			return nil, "", errors.New("invalid ed25519 private key")
+		}
+		ed25519key := ed25519.NewKeyFromSeed(k.Priv[:ed25519.SeedSize])
+		if subtle.ConstantTimeCompare(ed25519key, k.Priv) != 1 || !bytes.Equal(k.Pub, k.Priv[ed25519.SeedSize:]) {
+			return nil, "", errors.New("ed25519 private key does not match public key")
+		}
+		key, pubkey, comment = &ed25519key, ed25519key.Public(), k.Comment
+	default:
+		return nil, "", fmt.Errorf("unsupported openssh key type : %s", pk1.Keytype)
+	}
+
+	// the unencrypted public key of the file must be the public key of the private key
+	sshpubkey, err := ssh.NewPublicKey(pubkey)
+	if err != nil {
+		return nil, "", err
+	}
+	if !bytes.Equal(sshpubkey.Marshal(), w.PubKey) {
+		return nil, "", errors.New("openssh public key does not match private key")
+	}
+	return key, comment, nil
+}
+
+// EncodeOpenSSHPrivateKey encodes RSA / ECDSA / ED25519 private key to OpenSSH private key with the comment of encryptkey.
+// Private key is encrypted with bcrypt-pbkdf and aes256-ctr as ssh-keygen does when passphrase is not empty.
+func EncodeOpenSSHPrivateKey(encryptkey *entity.EncryptKey, passphrase []byte) ([]byte, error) {
+	var key interface{}
+	switch encryptkey.Keytype {
+	case entity.EncryptTypeRSA:
+		key = encryptkey.RsaKey.PrivateKey
+	case entity.EncryptTypeECDSA:
+		key = encryptkey.EcdsaKey.PrivateKey
+	case entity.EncryptTypeED25519:
+		if encryptkey.Ed25519Key.PrivateKey == nil {
+			return nil, errors.New("no private key")
+		}
+		key = *encryptkey.Ed25519Key.PrivateKey
+	default:
+		return nil, fmt.Errorf("openssh private key is not supported : %s", encryptkey.Keytype)
+	}
+	prikeybytes, err := MarshalOpenSSHPrivateKey(key, passphrase, encryptkey.Comment)
+	if err != nil {
+		return nil, err
+	}
+	pemdata := pem.EncodeToMemory(
+		&pem.Block{
+			Type:  blockTypeOpenSSHPrivateKey,
+			Bytes: prikeybytes,
+		},
+	)
+	return pemdata, nil
+}
+
+// MarshalOpenSSHPrivateKey marshals RSA / ECDSA / ED25519 private key to openssh-key-v1 bytes
+func MarshalOpenSSHPrivateKey(key interface{}, passphrase []byte, comment string) ([]byte, error) {
+	w := opensshKeyFile{
+		CipherName: opensshCipherNone,
+		KdfName:    opensshKdfNone,
+		NumKeys:    1,
+	}
+	blocksize := opensshBlockSize
+	var streamkey, iv []byte
+	if len(passphrase) > 0 {
+		c := opensshCiphers[opensshCipherAes256Ctr]
+		salt := make([]byte, opensshSaltSize)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, err
+		}
+		derived, err := bcrypt_pbkdf.Key(passphrase, salt, opensshRounds, c.keySize+aes.BlockSize)
+		if err != nil {
+			return nil, err
+		}
+		streamkey, iv = derived[:c.keySize], derived[c.keySize:]
+		w.CipherName = opensshCipherAes256Ctr
+		w.KdfName = opensshKdfBcrypt
+		w.KdfOpts = string(ssh.Marshal(opensshBcryptOptions{Salt: salt, Rounds: opensshRounds}))
+		blocksize = c.blockSize
+	}
+
+	var keytype string
+	var privkey []byte
+	var pubkey interface{}
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		if len(k.Primes) != 2 {
+			return nil, errors.New("multi-prime rsa private key is not supported")
+		}
+		k.Precompute()
+		keytype = ssh.KeyAlgoRSA
+		privkey = ssh.Marshal(opensshRsaPrivateKey{
+			N:       k.N,
+			E:       big.NewInt(int64(k.E)),
+			D:       k.D,
+			Iqmp:    k.Precomputed.Qinv,
+			P:       k.Primes[0],
+			Q:       k.Primes[1],
+			Comment: comment,
+		})
+		pubkey = &k.PublicKey
+	case *ecdsa.PrivateKey:
+		curvename, ok := opensshCurveNames[k.Curve]
+		if !ok {
+			return nil, errors.New("unsupported ecdsa curve")
+		}
+		keytype = "ecdsa-sha2-" + curvename
+		privkey = ssh.Marshal(opensshEcdsaPrivateKey{
+			Curve:   curvename,
+			Pub:     elliptic.Marshal(k.Curve, k.X, k.Y),
+			D:       k.D,
+			Comment: comment,
+		})
+		pubkey = &k.PublicKey
+	case ed25519.PrivateKey:
+		keytype = ssh.KeyAlgoED25519
+		privkey = ssh.Marshal(opensshEd25519PrivateKey{
+			Pub:     k.Public().(ed25519.PublicKey),
+			Priv:    k,
+			Comment: comment,
+		})
+		pubkey = k.Public()
+	case *ed25519.PrivateKey:
+		return MarshalOpenSSHPrivateKey(*k, passphrase, comment)
+	default:
+		return nil, errors.New("not RSA / ECDSA / ED25519 private key")
+	}
+	sshpubkey, err := ssh.NewPublicKey(pubkey)
+	if err != nil {
+		return nil, err
+	}
+	w.PubKey = sshpubkey.Marshal()
+
+	checkbytes := make([]byte, 4)
+	if _, err := rand.Read(checkbytes); err != nil {
+		return nil, err
+	}
+	check := binary.BigEndian.Uint32(checkbytes)
+	block := ssh.Marshal(opensshPrivateKeyHeader{
+		Check1:  check,
+		Check2:  check,
+		Keytype: keytype,
+		Rest:    privkey,
+	})
+	for i := 0; len(block)%blocksize != 0; i++ {
+		block = append(block, byte(i+1))
+	}
+
+	if streamkey != nil {
+		aesblock, err := aes.NewCipher(streamkey)
+		if err != nil {
+			return nil, err
+		}
+		cipher.NewCTR(aesblock, iv).XORKeyStream(block, block)
+	}
+	w.PrivKeyBlock = block
+
+	return append([]byte(opensshMagic), ssh.Marshal(w)...), nil
+}
+
+func decryptOpenSSHPrivateKeyBlock(w *opensshKeyFile, passphrase []byte) ([]byte, int, error) {
+	if w.CipherName == opensshCipherNone {
+		if w.KdfName != opensshKdfNone || len(w.KdfOpts) != 0 {
+			return nil, 0, errors.New("invalid openssh private key format")
+		}
+		if len(w.PrivKeyBlock) == 0 || len(w.PrivKeyBlock)%opensshBlockSize != 0 {
+			return nil, 0, errors.New("invalid openssh private key format")
+		}
+		return w.PrivKeyBlock, opensshBlockSize, nil
+	}
+	c, ok := opensshCiphers[w.CipherName]
+	if !ok {
+		return nil, 0, fmt.Errorf("unsupported openssh cipher : %s", w.CipherName)
+	}
+	if w.KdfName != opensshKdfBcrypt {
+		return nil, 0, fmt.Errorf("unsupported openssh kdf : %s", w.KdfName)
+	}
+	if len(passphrase) == 0 {
+		return nil, 0, errors.New("private key is encrypted, passphrase is required")
+	}
+	var opts opensshBcryptOptions
+	if err := ssh.Unmarshal([]byte(w.KdfOpts), &opts); err != nil {
+		return nil, 0, err
+	}
+	if len(opts.Salt) == 0 || len(opts.Salt) > opensshMaxSaltSize {
+		return nil, 0, errors.New("invalid bcrypt salt")
+	}
+	if opts.Rounds == 0 || opts.Rounds > opensshMaxRounds {
+		return nil, 0, errors.New("invalid bcrypt rounds")
+	}
+	if len(w.PrivKeyBlock) == 0 || len(w.PrivKeyBlock)%c.blockSize != 0 {
+		return nil, 0, errors.New("invalid openssh private key format")
+	}
+	derived, err := bcrypt_pbkdf.Key(passphrase, opts.Salt, int(opts.Rounds), c.keySize+c.blockSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	aesblock, err := aes.NewCipher(derived[:c.keySize])
+	if err != nil {
+		return nil, 0, err
+	}
+	privkeyblock := make([]byte, len(w.PrivKeyBlock))
+	c.stream(aesblock, derived[c.keySize:]).XORKeyStream(privkeyblock, w.PrivKeyBlock)
+	return privkeyblock, c.blockSize, nil
+}
+
+// checkOpenSSHPadding checks the padding is 1, 2, 3, ... and shorter than a block
+func checkOpenSSHPadding(pad []byte, blocksize int) error {
+	if len(pad) >= blocksize {
+		return errors.New("invalid openssh private key padding")
+	}
+	for i, b := range pad {
+		if int(b) != i+1 {
+			return errors.New("invalid openssh private key padding")
+		}
+	}
+	return nil
+}
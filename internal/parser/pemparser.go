@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"bytes"
+	"crypto/ecdh"
 	"crypto/ecdsa"
 	"crypto/ed25519"
 	"crypto/rsa"
@@ -8,10 +10,8 @@ import (
 	"encoding/pem"
 	"errors"
 	"fmt"
-	mathrand "math/rand"
-	"unsafe"
+	"strings"
 
-	"github.com/ScaleFT/sshkeys"
 	"github.com/howood/cryptotools/internal/entity"
 	"golang.org/x/crypto/ssh"
 )
@@ -33,6 +33,41 @@ func DecodePrivateKey(bytedata []byte, encryptkey *entity.EncryptKey) error {
 	if block == nil {
 		return errors.New("failed to decode private key data")
 	}
+	if block.Type == blockTypeEncryptedPrivateKey || x509.IsEncryptedPEMBlock(block) {
+		return errors.New("private key is encrypted, passphrase is required")
+	}
+	return decodePrivateKeyBlock(block, encryptkey)
+}
+
+// DecodePrivateKeyWithPassphrase decodes private key encrypted with passphrase to entity struct.
+// PKCS #8 ENCRYPTED PRIVATE KEY, legacy encrypted PEM with Proc-Type and DEK-Info headers and
+// OpenSSH private key protected with passphrase are supported. Unencrypted private key is also decoded.
+func DecodePrivateKeyWithPassphrase(bytedata, passphrase []byte, encryptkey *entity.EncryptKey) error {
+	block, _ := pem.Decode(bytedata)
+	if block == nil {
+		return errors.New("failed to decode private key data")
+	}
+	switch {
+	case block.Type == blockTypeEncryptedPrivateKey:
+		der, err := DecryptPKCS8PrivateKey(block.Bytes, passphrase)
+		if err != nil {
+			return err
+		}
+		block = &pem.Block{Type: blockTypePrivateKey, Bytes: der}
+	case x509.IsEncryptedPEMBlock(block):
+		// legacy encrypted PEM is insecure and only read to migrate existing keys
+		der, err := x509.DecryptPEMBlock(block, passphrase)
+		if err != nil {
+			return err
+		}
+		block = &pem.Block{Type: block.Type, Bytes: der}
+	case block.Type == blockTypeOpenSSHPrivateKey:
+		return DecodeOpenSSHPrivateKey(bytedata, passphrase, encryptkey)
+	}
+	return decodePrivateKeyBlock(block, encryptkey)
+}
+
+func decodePrivateKeyBlock(block *pem.Block, encryptkey *entity.EncryptKey) error {
 	switch block.Type {
 	case blockTypeRsaPrivateKey:
 		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
@@ -50,13 +85,14 @@ func DecodePrivateKey(bytedata []byte, encryptkey *entity.EncryptKey) error {
 		}
 		encryptkey.Keytype = entity.EncryptTypeECDSA
 	case blockTypeOpenSSHPrivateKey:
-		keyInterface, err := sshkeys.ParseEncryptedRawPrivateKey(bytedata, nil)
+		keyInterface, comment, err := ParseOpenSSHPrivateKey(block.Bytes, nil)
 		if err != nil {
 			return err
 		}
 		if err := castPrivateKeyToEncryptKey(keyInterface, encryptkey); err != nil {
 			return err
 		}
+		encryptkey.Comment = comment
 	case blockTypePrivateKey:
 		keyInterface, err := x509.ParsePKCS8PrivateKey(block.Bytes)
 		if err != nil {
@@ -84,15 +120,8 @@ func DecodePublicKey(bytedata []byte, encryptkey *entity.EncryptKey) error {
 			return err
 		}
 		encryptkey.Keytype = entity.EncryptTypeRSA
-	case blockTypeEcdsaPublicKey:
-		keyInterface, err := x509.ParsePKIXPublicKey(block.Bytes)
-		if err != nil {
-			return err
-		}
-		if err := castPublicKeyToEncryptKey(keyInterface, encryptkey); err != nil {
-			return err
-		}
-	case blockTypeOpenSSHPublicKey, blockTypePublicKey:
+	case blockTypeEcdsaPublicKey, blockTypeOpenSSHPublicKey, blockTypePublicKey:
+		// EC PUBLIC KEY and OPENSSH PUBLIC KEY are PKIX public key written by older versions
 		keyInterface, err := x509.ParsePKIXPublicKey(block.Bytes)
 		if err != nil {
 			return err
@@ -108,14 +137,70 @@ func DecodePublicKey(bytedata []byte, encryptkey *entity.EncryptKey) error {
 
 // DecodeAuthorizedKey decodes authorizedkey to entity struct
 func DecodeAuthorizedKey(input []byte, encryptkey *entity.EncryptKey) error {
-	pkey, _, _, _, err := ssh.ParseAuthorizedKey(input)
+	pkey, comment, options, _, err := ssh.ParseAuthorizedKey(input)
 	if err != nil {
 		return err
 	}
-	if pkey, ok := pkey.(ssh.CryptoPublicKey); ok {
-		return castPublicKeyToEncryptKey(pkey.CryptoPublicKey(), encryptkey)
+	cryptopkey, ok := pkey.(ssh.CryptoPublicKey)
+	if !ok {
+		return errors.New("not RSA / ECDSA / ED25519 public key")
+	}
+	if err := castPublicKeyToEncryptKey(cryptopkey.CryptoPublicKey(), encryptkey); err != nil {
+		return err
 	}
-	return errors.New("not RSA / ECDSA / ED25519 public key")
+	encryptkey.Comment = comment
+	encryptkey.Options = options
+	return nil
+}
+
+// EncodeAuthorizedKey encodes RSA / ECDSA / ED25519 public key to OpenSSH authorized_keys line with the options and the comment
+func EncodeAuthorizedKey(encryptkey *entity.EncryptKey) ([]byte, error) {
+	var pubkey interface{}
+	switch encryptkey.Keytype {
+	case entity.EncryptTypeRSA:
+		pubkey = encryptkey.RsaKey.PublicKey
+	case entity.EncryptTypeECDSA:
+		pubkey = encryptkey.EcdsaKey.PublicKey
+	case entity.EncryptTypeED25519:
+		if encryptkey.Ed25519Key.PublicKey == nil {
+			return nil, errors.New("no public key")
+		}
+		pubkey = *encryptkey.Ed25519Key.PublicKey
+	default:
+		return nil, fmt.Errorf("authorized key is not supported : %s", encryptkey.Keytype)
+	}
+	sshpubkey, err := ssh.NewPublicKey(pubkey)
+	if err != nil {
+		return nil, err
+	}
+	line := bytes.TrimSuffix(ssh.MarshalAuthorizedKey(sshpubkey), []byte("\n"))
+	if len(encryptkey.Options) > 0 {
+		line = append([]byte(strings.Join(encryptkey.Options, ",")+" "), line...)
+	}
+	if encryptkey.Comment != "" {
+		line = append(line, []byte(" "+encryptkey.Comment)...)
+	}
+	return append(line, '\n'), nil
+}
+
+// DecodeAuthorizedKeys decodes every key in authorized_keys file to entity structs
+func DecodeAuthorizedKeys(input []byte) ([]entity.EncryptKey, error) {
+	encryptkeys := make([]entity.EncryptKey, 0)
+	for i, line := range bytes.Split(input, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		encryptkey := entity.EncryptKey{}
+		if err := DecodeAuthorizedKey(line, &encryptkey); err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		encryptkeys = append(encryptkeys, encryptkey)
+	}
+	if len(encryptkeys) == 0 {
+		return nil, errors.New("no authorized key found")
+	}
+	return encryptkeys, nil
 }
 
 // EncodePrivateKey decodes private key to bytes
@@ -126,12 +211,44 @@ func EncodePrivateKey(encryptkey *entity.EncryptKey) ([]byte, error) {
 	case entity.EncryptTypeECDSA:
 		return EncodeEcdsaPrivateKey(encryptkey.EcdsaKey.PrivateKey)
 	case entity.EncryptTypeED25519:
-		return EncodeEd25519PrivateKey(encryptkey.Ed25519Key.PrivateKey), nil
+		return EncodeOpenSSHPrivateKey(encryptkey, nil)
+	case entity.EncryptTypeX25519:
+		return EncodeX25519PrivateKey(encryptkey.X25519Key.PrivateKey)
 	default:
 		return nil, errors.New("No encryptkey KeyType")
 	}
 }
 
+// EncodePrivateKeyStrict encodes private key to bytes with standard PEM labels.
+// RSA is PKCS1 RSA PRIVATE KEY, ECDSA is SEC1 EC PRIVATE KEY, and ED25519 and X25519 are PKCS8 PRIVATE KEY.
+func EncodePrivateKeyStrict(encryptkey *entity.EncryptKey) ([]byte, error) {
+	switch encryptkey.Keytype {
+	case entity.EncryptTypeRSA:
+		return EncodeRsaPrivateKeyPKCS1(encryptkey.RsaKey.PrivateKey), nil
+	case entity.EncryptTypeECDSA:
+		return EncodeEcdsaPrivateKey(encryptkey.EcdsaKey.PrivateKey)
+	case entity.EncryptTypeED25519, entity.EncryptTypeX25519:
+		return EncodePKCS8PrivateKey(encryptkey)
+	default:
+		return nil, errors.New("No encryptkey KeyType")
+	}
+}
+
+// EncodePKCS8PrivateKey encodes PKCS8 private key of any key type to bytes
+func EncodePKCS8PrivateKey(encryptkey *entity.EncryptKey) ([]byte, error) {
+	prikeybytes, err := MarshalPKCS8PrivateKey(encryptkey)
+	if err != nil {
+		return nil, err
+	}
+	pemdata := pem.EncodeToMemory(
+		&pem.Block{
+			Type:  blockTypePrivateKey,
+			Bytes: prikeybytes,
+		},
+	)
+	return pemdata, nil
+}
+
 // EncodeRsaPrivateKeyPKCS1 encodes PKCS1 private key to bytes
 func EncodeRsaPrivateKeyPKCS1(prikey *rsa.PrivateKey) []byte {
 	prikeybytes := x509.MarshalPKCS1PrivateKey(prikey)
@@ -186,6 +303,21 @@ func EncodeEd25519PrivateKey(prikey *ed25519.PrivateKey) []byte {
 	return pemdata
 }
 
+// EncodeX25519PrivateKey encodes X25519 PKCS8 private key to bytes
+func EncodeX25519PrivateKey(prikey *ecdh.PrivateKey) ([]byte, error) {
+	prikeybytes, err := x509.MarshalPKCS8PrivateKey(prikey)
+	if err != nil {
+		return nil, err
+	}
+	pemdata := pem.EncodeToMemory(
+		&pem.Block{
+			Type:  blockTypePrivateKey,
+			Bytes: prikeybytes,
+		},
+	)
+	return pemdata, nil
+}
+
 // EncodePublicKey encodes public key to bytes
 func EncodePublicKey(encryptkey *entity.EncryptKey) ([]byte, error) {
 	switch encryptkey.Keytype {
@@ -195,11 +327,58 @@ func EncodePublicKey(encryptkey *entity.EncryptKey) ([]byte, error) {
 		return EncodeEcdsaPublicKey(encryptkey.EcdsaKey.PublicKey)
 	case entity.EncryptTypeED25519:
 		return EncodeED25519PublicKey(encryptkey.Ed25519Key.PublicKey)
+	case entity.EncryptTypeX25519:
+		return EncodeX25519PublicKey(encryptkey.X25519Key.PublicKey)
 	default:
 		return nil, errors.New("No encryptkey KeyType")
 	}
 }
 
+// EncodePublicKeyStrict encodes PKIX public key of any key type to bytes with standard PUBLIC KEY label
+func EncodePublicKeyStrict(encryptkey *entity.EncryptKey) ([]byte, error) {
+	pubkeybytes, err := MarshalPKIXPublicKey(encryptkey)
+	if err != nil {
+		return nil, err
+	}
+	pemdata := pem.EncodeToMemory(
+		&pem.Block{
+			Type:  blockTypePublicKey,
+			Bytes: pubkeybytes,
+		},
+	)
+	return pemdata, nil
+}
+
+// MarshalPKIXPublicKey marshals public key of any key type to PKIX DER bytes
+func MarshalPKIXPublicKey(encryptkey *entity.EncryptKey) ([]byte, error) {
+	switch encryptkey.Keytype {
+	case entity.EncryptTypeRSA:
+		return x509.MarshalPKIXPublicKey(encryptkey.RsaKey.PublicKey)
+	case entity.EncryptTypeECDSA:
+		return x509.MarshalPKIXPublicKey(encryptkey.EcdsaKey.PublicKey)
+	case entity.EncryptTypeED25519:
+		if encryptkey.Ed25519Key.PublicKey == nil {
+			return nil, errors.New("no public key")
+		}
+		return x509.MarshalPKIXPublicKey(*encryptkey.Ed25519Key.PublicKey)
+	case entity.EncryptTypeX25519:
+		return x509.MarshalPKIXPublicKey(encryptkey.X25519Key.PublicKey)
+	default:
+		return nil, errors.New("No encryptkey KeyType")
+	}
+}
+
+// EncodeRsaPublicKeyPKCS1 encodes PKCS1 public key to bytes
+func EncodeRsaPublicKeyPKCS1(pubkey *rsa.PublicKey) []byte {
+	pemdata := pem.EncodeToMemory(
+		&pem.Block{
+			Type:  blockTypeRsaPublicKey,
+			Bytes: x509.MarshalPKCS1PublicKey(pubkey),
+		},
+	)
+	return pemdata
+}
+
 // EncodeRsaPublicKey encodes public key to bytes
 func EncodeRsaPublicKey(pubkey *rsa.PublicKey) ([]byte, error) {
 	prikeybytes, err := x509.MarshalPKIXPublicKey(pubkey)
@@ -245,6 +424,21 @@ func EncodeED25519PublicKey(pubkey *ed25519.PublicKey) ([]byte, error) {
 	return pemdata, nil
 }
 
+// EncodeX25519PublicKey encodes X25519 public key to bytes
+func EncodeX25519PublicKey(pubkey *ecdh.PublicKey) ([]byte, error) {
+	pubkeybytes, err := x509.MarshalPKIXPublicKey(pubkey)
+	if err != nil {
+		return nil, err
+	}
+	pemdata := pem.EncodeToMemory(
+		&pem.Block{
+			Type:  blockTypePublicKey,
+			Bytes: pubkeybytes,
+		},
+	)
+	return pemdata, nil
+}
+
 func castPrivateKeyToEncryptKey(keyInterface interface{}, encryptkey *entity.EncryptKey) error {
 	switch priv := keyInterface.(type) {
 	case *rsa.PrivateKey:
@@ -264,8 +458,15 @@ func castPrivateKeyToEncryptKey(keyInterface interface{}, encryptkey *entity.Enc
 		encryptkey.Ed25519Key.PrivateKey = &priv
 		encryptkey.Keytype = entity.EncryptTypeED25519
 		return nil
+	case *ecdh.PrivateKey:
+		if priv.Curve() != ecdh.X25519() {
+			return errors.New("not X25519 private key")
+		}
+		encryptkey.X25519Key.PrivateKey = priv
+		encryptkey.Keytype = entity.EncryptTypeX25519
+		return nil
 	default:
-		return errors.New("not RSA / ECDSA / ED25519 private key")
+		return errors.New("not RSA / ECDSA / ED25519 / X25519 private key")
 	}
 }
 
@@ -287,69 +488,23 @@ func castPublicKeyToEncryptKey(keyInterface interface{}, encryptkey *entity.Encr
 		encryptkey.Ed25519Key.PublicKey = &priv
 		encryptkey.Keytype = entity.EncryptTypeED25519
 		return nil
+	case *ecdh.PublicKey:
+		if priv.Curve() != ecdh.X25519() {
+			return errors.New("not X25519 public key")
+		}
+		encryptkey.X25519Key.PublicKey = priv
+		encryptkey.Keytype = entity.EncryptTypeX25519
+		return nil
 	default:
-		return errors.New("not RSA / ECDSA / ED25519 public key")
+		return errors.New("not RSA / ECDSA / ED25519 / X25519 public key")
 	}
 }
 
 // MarshalED25519PrivateKey marshal ED25519 privatekey to bytes
 func MarshalED25519PrivateKey(key *ed25519.PrivateKey) []byte {
-	magic := append([]byte("openssh-key-v1"), 0)
-
-	var w struct {
-		CipherName   string
-		KdfName      string
-		KdfOpts      string
-		NumKeys      uint32
-		PubKey       []byte
-		PrivKeyBlock []byte
-	}
-
-	pk1 := struct {
-		Check1  uint32
-		Check2  uint32
-		Keytype string
-		Pub     []byte
-		Priv    []byte
-		Comment string
-		Pad     []byte `ssh:"rest"`
-	}{}
-
-	ci := mathrand.Uint32()
-	pk1.Check1 = ci
-	pk1.Check2 = ci
-	pk1.Keytype = ssh.KeyAlgoED25519
-
-	pk, ok := key.Public().(ed25519.PublicKey)
-	if !ok {
+	prikeybytes, err := MarshalOpenSSHPrivateKey(*key, nil, "")
+	if err != nil {
 		return nil
 	}
-	pubKey := []byte(pk)
-	pk1.Pub = pubKey
-	pk1.Priv = []byte(*(*[]byte)(unsafe.Pointer(key)))
-	pk1.Comment = ""
-
-	bs := 8
-	blockLen := len(ssh.Marshal(pk1))
-	padLen := (bs - (blockLen % bs)) % bs
-	pk1.Pad = make([]byte, padLen)
-
-	for i := 0; i < padLen; i++ {
-		pk1.Pad[i] = byte(i + 1)
-	}
-
-	prefix := []byte{0x0, 0x0, 0x0, 0x0b}
-	prefix = append(prefix, []byte(ssh.KeyAlgoED25519)...)
-	prefix = append(prefix, []byte{0x0, 0x0, 0x0, 0x20}...)
-
-	w.CipherName = "none"
-	w.KdfName = "none"
-	w.KdfOpts = ""
-	w.NumKeys = 1
-	w.PubKey = append(prefix, pubKey...)
-	w.PrivKeyBlock = ssh.Marshal(pk1)
-
-	magic = append(magic, ssh.Marshal(w)...)
-
-	return magic
+	return prikeybytes
 }
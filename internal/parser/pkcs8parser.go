@@ -0,0 +1,327 @@
+package parser
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+
+	"github.com/howood/cryptotools/internal/entity"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	blockTypeEncryptedPrivateKey = "ENCRYPTED PRIVATE KEY"
+
+	pkcs8SaltSize         = 16
+	pkcs8GcmNonceSize     = 12
+	pkcs8ScryptCost       = 1 << 14
+	pkcs8ScryptBlockSize  = 8
+	pkcs8ScryptParallel   = 1
+	pkcs8PBKDF2Iterations = 600000
+
+	// upper limits for parameters read from encrypted private key, to avoid exhausting resources
+	maxPkcs8ScryptCost       = 1 << 20
+	maxPkcs8ScryptBlockSize  = 16
+	maxPkcs8ScryptParallel   = 4
+	maxPkcs8ScryptMemory     = 256 << 20 // scrypt uses 128 * r * N bytes
+	maxPkcs8PBKDF2Iterations = 2000000
+)
+
+var (
+	oidPBES2          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidScrypt         = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11591, 4, 11}
+	oidHMACWithSHA1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidHMACWithSHA384 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 10}
+	oidHMACWithSHA512 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+	oidAES128CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidAES256GCM      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 46}
+	errorPkcs8Params  = errors.New("invalid encrypted private key parameters")
+)
+
+// encryptedPrivateKeyInfo is EncryptedPrivateKeyInfo defined in RFC 5958
+type encryptedPrivateKeyInfo struct {
+	EncryptionAlgorithm pkix.AlgorithmIdentifier
+	EncryptedData       []byte
+}
+
+// pbes2Params is PBES2-params defined in RFC 8018
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+// pbkdf2Params is PBKDF2-params defined in RFC 8018. PRF is hmacWithSHA1 when omitted
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	KeyLength      int                      `asn1:"optional"`
+	PRF            pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+// scryptParams is scrypt-params defined in RFC 7914
+type scryptParams struct {
+	Salt                     []byte
+	CostParameter            int
+	BlockSize                int
+	ParallelizationParameter int
+	KeyLength                int `asn1:"optional"`
+}
+
+// gcmParams is GCMParameters defined in RFC 5084
+type gcmParams struct {
+	Nonce  []byte
+	ICVLen int `asn1:"default:12,optional"`
+}
+
+// MarshalPKCS8PrivateKey marshals private key to PKCS #8 DER
+func MarshalPKCS8PrivateKey(encryptkey *entity.EncryptKey) ([]byte, error) {
+	switch encryptkey.Keytype {
+	case entity.EncryptTypeRSA:
+		return x509.MarshalPKCS8PrivateKey(encryptkey.RsaKey.PrivateKey)
+	case entity.EncryptTypeECDSA:
+		return x509.MarshalPKCS8PrivateKey(encryptkey.EcdsaKey.PrivateKey)
+	case entity.EncryptTypeED25519:
+		if encryptkey.Ed25519Key.PrivateKey == nil {
+			return nil, errors.New("no private key")
+		}
+		return x509.MarshalPKCS8PrivateKey(*encryptkey.Ed25519Key.PrivateKey)
+	case entity.EncryptTypeX25519:
+		return x509.MarshalPKCS8PrivateKey(encryptkey.X25519Key.PrivateKey)
+	default:
+		return nil, errors.New("No encryptkey KeyType")
+	}
+}
+
+// EncodeEncryptedPrivateKey encodes private key to PKCS #8 PBES2 encrypted PEM
+func EncodeEncryptedPrivateKey(encryptkey *entity.EncryptKey, passphrase []byte, options entity.PrivateKeyEncryption) ([]byte, error) {
+	der, err := MarshalPKCS8PrivateKey(encryptkey)
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := EncryptPKCS8PrivateKey(der, passphrase, options)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: blockTypeEncryptedPrivateKey, Bytes: encrypted}), nil
+}
+
+// EncryptPKCS8PrivateKey encrypts PKCS #8 DER private key to EncryptedPrivateKeyInfo DER with PBES2
+func EncryptPKCS8PrivateKey(der, passphrase []byte, options entity.PrivateKeyEncryption) ([]byte, error) {
+	salt := make([]byte, pkcs8SaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	var kdf pkix.AlgorithmIdentifier
+	var key []byte
+	switch options.KDF {
+	case entity.PrivateKeyKDFScrypt, "":
+		params, err := asn1.Marshal(scryptParams{
+			Salt:                     salt,
+			CostParameter:            pkcs8ScryptCost,
+			BlockSize:                pkcs8ScryptBlockSize,
+			ParallelizationParameter: pkcs8ScryptParallel,
+		})
+		if err != nil {
+			return nil, err
+		}
+		kdf = pkix.AlgorithmIdentifier{Algorithm: oidScrypt, Parameters: asn1.RawValue{FullBytes: params}}
+		if key, err = scrypt.Key(passphrase, salt, pkcs8ScryptCost, pkcs8ScryptBlockSize, pkcs8ScryptParallel, 32); err != nil {
+			return nil, err
+		}
+	case entity.PrivateKeyKDFPBKDF2SHA256:
+		params, err := asn1.Marshal(pbkdf2Params{
+			Salt:           salt,
+			IterationCount: pkcs8PBKDF2Iterations,
+			PRF:            pkix.AlgorithmIdentifier{Algorithm: oidHMACWithSHA256, Parameters: asn1.NullRawValue},
+		})
+		if err != nil {
+			return nil, err
+		}
+		kdf = pkix.AlgorithmIdentifier{Algorithm: oidPBKDF2, Parameters: asn1.RawValue{FullBytes: params}}
+		key = pbkdf2.Key(passphrase, salt, pkcs8PBKDF2Iterations, 32, sha256.New)
+	default:
+		return nil, fmt.Errorf("invalid private key KDF : %s", options.KDF)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	var scheme pkix.AlgorithmIdentifier
+	var encrypted []byte
+	switch options.Cipher {
+	case entity.PrivateKeyCipherAes256Cbc, "":
+		iv := make([]byte, aes.BlockSize)
+		if _, err := rand.Read(iv); err != nil {
+			return nil, err
+		}
+		params, err := asn1.Marshal(iv)
+		if err != nil {
+			return nil, err
+		}
+		scheme = pkix.AlgorithmIdentifier{Algorithm: oidAES256CBC, Parameters: asn1.RawValue{FullBytes: params}}
+		padLen := aes.BlockSize - len(der)%aes.BlockSize
+		encrypted = append(append([]byte{}, der...), bytes.Repeat([]byte{byte(padLen)}, padLen)...)
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, encrypted)
+	case entity.PrivateKeyCipherAes256Gcm:
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		nonce := make([]byte, aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return nil, err
+		}
+		params, err := asn1.Marshal(gcmParams{Nonce: nonce, ICVLen: aead.Overhead()})
+		if err != nil {
+			return nil, err
+		}
+		scheme = pkix.AlgorithmIdentifier{Algorithm: oidAES256GCM, Parameters: asn1.RawValue{FullBytes: params}}
+		encrypted = aead.Seal(nil, nonce, der, nil)
+	default:
+		return nil, fmt.Errorf("invalid private key cipher : %s", options.Cipher)
+	}
+
+	params, err := asn1.Marshal(pbes2Params{KeyDerivationFunc: kdf, EncryptionScheme: scheme})
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(encryptedPrivateKeyInfo{
+		EncryptionAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidPBES2, Parameters: asn1.RawValue{FullBytes: params}},
+		EncryptedData:       encrypted,
+	})
+}
+
+// DecryptPKCS8PrivateKey decrypts EncryptedPrivateKeyInfo DER with PBES2 to PKCS #8 DER private key.
+// PBKDF2 (HMAC-SHA1, SHA-256, SHA-384 or SHA-512) or scrypt with AES-CBC or AES-GCM is supported.
+func DecryptPKCS8PrivateKey(der, passphrase []byte) ([]byte, error) {
+	var info encryptedPrivateKeyInfo
+	if rest, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, err
+	} else if len(rest) > 0 {
+		return nil, errorPkcs8Params
+	}
+	if !info.EncryptionAlgorithm.Algorithm.Equal(oidPBES2) {
+		return nil, fmt.Errorf("unsupported private key encryption : %s", info.EncryptionAlgorithm.Algorithm)
+	}
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(info.EncryptionAlgorithm.Parameters.FullBytes, &params); err != nil {
+		return nil, err
+	}
+
+	var keyLen int
+	var gcm bool
+	scheme := params.EncryptionScheme
+	switch {
+	case scheme.Algorithm.Equal(oidAES128CBC):
+		keyLen = 16
+	case scheme.Algorithm.Equal(oidAES192CBC):
+		keyLen = 24
+	case scheme.Algorithm.Equal(oidAES256CBC):
+		keyLen = 32
+	case scheme.Algorithm.Equal(oidAES256GCM):
+		keyLen = 32
+		gcm = true
+	default:
+		return nil, fmt.Errorf("unsupported private key cipher : %s", scheme.Algorithm)
+	}
+	key, err := derivePkcs8Key(params.KeyDerivationFunc, passphrase, keyLen)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if gcm {
+		var gcmparams gcmParams
+		if _, err := asn1.Unmarshal(scheme.Parameters.FullBytes, &gcmparams); err != nil {
+			return nil, err
+		}
+		if len(gcmparams.Nonce) != pkcs8GcmNonceSize || gcmparams.ICVLen < 12 || gcmparams.ICVLen > 16 {
+			return nil, errorPkcs8Params
+		}
+		aead, err := cipher.NewGCMWithTagSize(block, gcmparams.ICVLen)
+		if err != nil {
+			return nil, err
+		}
+		decrypted, err := aead.Open(nil, gcmparams.Nonce, info.EncryptedData, nil)
+		if err != nil {
+			return nil, errors.New("failed to decrypt private key: wrong passphrase or corrupted data")
+		}
+		return decrypted, nil
+	}
+
+	var iv []byte
+	if _, err := asn1.Unmarshal(scheme.Parameters.FullBytes, &iv); err != nil {
+		return nil, err
+	}
+	if len(iv) != aes.BlockSize || len(info.EncryptedData) == 0 || len(info.EncryptedData)%aes.BlockSize != 0 {
+		return nil, errorPkcs8Params
+	}
+	decrypted := make([]byte, len(info.EncryptedData))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(decrypted, info.EncryptedData)
+	padLen := int(decrypted[len(decrypted)-1])
+	if padLen == 0 || padLen > aes.BlockSize || !bytes.Equal(decrypted[len(decrypted)-padLen:], bytes.Repeat([]byte{byte(padLen)}, padLen)) {
+		return nil, errors.New("failed to decrypt private key: wrong passphrase or corrupted data")
+	}
+	return decrypted[:len(decrypted)-padLen], nil
+}
+
+func derivePkcs8Key(kdf pkix.AlgorithmIdentifier, passphrase []byte, keyLen int) ([]byte, error) {
+	switch {
+	case kdf.Algorithm.Equal(oidPBKDF2):
+		var params pbkdf2Params
+		if _, err := asn1.Unmarshal(kdf.Parameters.FullBytes, &params); err != nil {
+			return nil, err
+		}
+		if len(params.Salt) == 0 || params.IterationCount <= 0 || params.IterationCount > maxPkcs8PBKDF2Iterations || (params.KeyLength != 0 && params.KeyLength != keyLen) {
+			return nil, errorPkcs8Params
+		}
+		var prf func() hash.Hash
+		switch {
+		case len(params.PRF.Algorithm) == 0, params.PRF.Algorithm.Equal(oidHMACWithSHA1):
+			prf = sha1.New
+		case params.PRF.Algorithm.Equal(oidHMACWithSHA256):
+			prf = sha256.New
+		case params.PRF.Algorithm.Equal(oidHMACWithSHA384):
+			prf = sha512.New384
+		case params.PRF.Algorithm.Equal(oidHMACWithSHA512):
+			prf = sha512.New
+		default:
+			return nil, fmt.Errorf("unsupported PBKDF2 PRF : %s", params.PRF.Algorithm)
+		}
+		return pbkdf2.Key(passphrase, params.Salt, params.IterationCount, keyLen, prf), nil
+	case kdf.Algorithm.Equal(oidScrypt):
+		var params scryptParams
+		if _, err := asn1.Unmarshal(kdf.Parameters.FullBytes, &params); err != nil {
+			return nil, err
+		}
+		if len(params.Salt) == 0 || params.CostParameter <= 1 || params.CostParameter > maxPkcs8ScryptCost || params.BlockSize <= 0 || params.BlockSize > maxPkcs8ScryptBlockSize ||
+			params.ParallelizationParameter <= 0 || params.ParallelizationParameter > maxPkcs8ScryptParallel || (params.KeyLength != 0 && params.KeyLength != keyLen) {
+			return nil, errorPkcs8Params
+		}
+		if 128*int64(params.BlockSize)*int64(params.CostParameter) > maxPkcs8ScryptMemory {
+			return nil, errorPkcs8Params
+		}
+		return scrypt.Key(passphrase, params.Salt, params.CostParameter, params.BlockSize, params.ParallelizationParameter, keyLen)
+	default:
+		return nil, fmt.Errorf("unsupported private key KDF : %s", kdf.Algorithm)
+	}
+}
@@ -1,8 +1,10 @@
 package parser
 
 import (
+	"crypto"
 	"crypto/rsa"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/howood/cryptotools/internal/entity"
@@ -620,7 +622,7 @@ btwv7AzaptOn66KJ7tEo92yJbMdZvOxQfCLnjk9FqoLqFAPtiRXoqlNUfK7nAj9z
 Sflwh6m3w5TyQziTOp9O468CAwEAAQ==
 -----END PUBLIC KEY-----`
 
-	checkdata := `{"kty":"RSA","kid":"aaaa","alg":"RS256","n":"opHmB0gquCxhX46Tn-O5EfiAVUmjrCqOJLeZ0wZEaZpNDOUpbKO6Vb_MEJUiXfzoRH6Q23H3inzl02XQE1HFwdV7EuT1ineHAL7h1MVwkFX2iBzPxuyg0pGl_yDg8aSWIWT0E-dlbAGXqzz6uISsqURRUQYrVlirDV8JnQd3QHE87u4g5E00fKmwuH6viFlVr_zwvAU0airh0Fk4AgikQdJxiGIzfOCapmy-LlnDb7Hu0bH9fUK04rr57F3LU_-9QPG7FoLYVFjg08dwlo8O_sGMad8K7UGHuZBIyRz5SA9nmaCTj5xGjmpsJ1ZL8W5puUs_OWSFkIuDhPix-5vok_4Iw4O5jDiFGadJqHpfEGhZWL0037gg7X0YaFudvsdZdHrr9oFI3NUeAJGq82quns_9c1ARiYssmt3YP5dkVilSfX0U7vM1QYcwffmH48-V7t-GN09PdFdN-iXJQlg9nwzE573YHTXWdzgrN3KqGoRcaBCRcaq-nRW8RcHReIkxgAdXzQl2sSJLphZNH4pfnSrR7ChAEk1ZR567L-malLKARkI3lNGlMFGvhjxpH1cHJeYGo2bHzYtbVhoNujF6btwv7AzaptOn66KJ7tEo92yJbMdZvOxQfCLnjk9FqoLqFAPtiRXoqlNUfK7nAj9zSflwh6m3w5TyQziTOp9O468","e":"AQAB"}`
+	checkdata := `{"kty":"RSA","kid":"aaaa","n":"opHmB0gquCxhX46Tn-O5EfiAVUmjrCqOJLeZ0wZEaZpNDOUpbKO6Vb_MEJUiXfzoRH6Q23H3inzl02XQE1HFwdV7EuT1ineHAL7h1MVwkFX2iBzPxuyg0pGl_yDg8aSWIWT0E-dlbAGXqzz6uISsqURRUQYrVlirDV8JnQd3QHE87u4g5E00fKmwuH6viFlVr_zwvAU0airh0Fk4AgikQdJxiGIzfOCapmy-LlnDb7Hu0bH9fUK04rr57F3LU_-9QPG7FoLYVFjg08dwlo8O_sGMad8K7UGHuZBIyRz5SA9nmaCTj5xGjmpsJ1ZL8W5puUs_OWSFkIuDhPix-5vok_4Iw4O5jDiFGadJqHpfEGhZWL0037gg7X0YaFudvsdZdHrr9oFI3NUeAJGq82quns_9c1ARiYssmt3YP5dkVilSfX0U7vM1QYcwffmH48-V7t-GN09PdFdN-iXJQlg9nwzE573YHTXWdzgrN3KqGoRcaBCRcaq-nRW8RcHReIkxgAdXzQl2sSJLphZNH4pfnSrR7ChAEk1ZR567L-malLKARkI3lNGlMFGvhjxpH1cHJeYGo2bHzYtbVhoNujF6btwv7AzaptOn66KJ7tEo92yJbMdZvOxQfCLnjk9FqoLqFAPtiRXoqlNUfK7nAj9zSflwh6m3w5TyQziTOp9O468","e":"AQAB"}`
 
 	encryptkey := &entity.EncryptKey{}
 	err := DecodePublicKey([]byte(publickey), encryptkey)
@@ -883,3 +885,123 @@ func Test_ConvertAuthorizedKeyToPEM(t *testing.T) {
 		t.Logf("success : %s", k)
 	}
 }
+
+func Test_EncodeAuthorizedKey(t *testing.T) {
+	for k, v := range authorizedKeyData {
+		if v.ResultHasErr {
+			continue
+		}
+		encryptkey := &entity.EncryptKey{}
+		if err := DecodeAuthorizedKey([]byte(v.Data), encryptkey); err != nil {
+			t.Fatalf("failed test :%s %#v", k, err)
+		}
+		encryptkey.Comment = "user@cryptotools"
+		encryptkey.Options = []string{`command="echo hello"`, "no-pty"}
+		data, err := EncodeAuthorizedKey(encryptkey)
+		if err != nil {
+			t.Fatalf("failed test :%s %#v", k, err)
+		}
+		decoded := &entity.EncryptKey{}
+		if err := DecodeAuthorizedKey(data, decoded); err != nil {
+			t.Fatalf("failed test :%s %#v", k, err)
+		}
+		if decoded.Keytype != encryptkey.Keytype || decoded.Comment != encryptkey.Comment || !reflect.DeepEqual(decoded.Options, encryptkey.Options) {
+			t.Fatalf("failed EncodeAuthorizedKey :%s %s", k, string(data))
+		}
+	}
+	t.Log("success EncodeAuthorizedKey")
+}
+
+func Test_EncodeStrict(t *testing.T) {
+	for k, v := range privatekeyData {
+		if v.ResultHasErr {
+			continue
+		}
+		encryptkey := &entity.EncryptKey{}
+		if err := DecodePrivateKey([]byte(v.Data), encryptkey); err != nil {
+			t.Fatalf("failed test :%s %#v", k, err)
+		}
+		for _, encode := range []func(*entity.EncryptKey) ([]byte, error){EncodePrivateKeyStrict, EncodePKCS8PrivateKey} {
+			data, err := encode(encryptkey)
+			if err != nil {
+				t.Fatalf("failed test :%s %#v", k, err)
+			}
+			if strings.Contains(string(data), "OPENSSH") {
+				t.Fatalf("failed EncodePrivateKeyStrict :%s %s", k, string(data))
+			}
+			decoded := &entity.EncryptKey{}
+			if err := DecodePrivateKey(data, decoded); err != nil {
+				t.Fatalf("failed test :%s %#v", k, err)
+			}
+			if decoded.Keytype != encryptkey.Keytype {
+				t.Fatalf("failed EncodePrivateKeyStrict :%s %s", k, decoded.Keytype)
+			}
+		}
+	}
+	for k, v := range publickeyData {
+		if v.ResultHasErr {
+			continue
+		}
+		encryptkey := &entity.EncryptKey{}
+		if err := DecodePublicKey([]byte(v.Data), encryptkey); err != nil {
+			t.Fatalf("failed test :%s %#v", k, err)
+		}
+		data, err := EncodePublicKeyStrict(encryptkey)
+		if err != nil {
+			t.Fatalf("failed test :%s %#v", k, err)
+		}
+		if !strings.HasPrefix(string(data), "-----BEGIN PUBLIC KEY-----") {
+			t.Fatalf("failed EncodePublicKeyStrict :%s %s", k, string(data))
+		}
+		decoded := &entity.EncryptKey{}
+		if err := DecodePublicKey(data, decoded); err != nil {
+			t.Fatalf("failed test :%s %#v", k, err)
+		}
+		if decoded.Keytype != encryptkey.Keytype {
+			t.Fatalf("failed EncodePublicKeyStrict :%s %s", k, decoded.Keytype)
+		}
+		if encryptkey.Keytype == entity.EncryptTypeRSA {
+			decoded := &entity.EncryptKey{}
+			if err := DecodePublicKey(EncodeRsaPublicKeyPKCS1(encryptkey.RsaKey.PublicKey), decoded); err != nil {
+				t.Fatalf("failed test :%s %#v", k, err)
+			}
+			if !decoded.RsaKey.PublicKey.Equal(encryptkey.RsaKey.PublicKey) {
+				t.Fatalf("failed EncodeRsaPublicKeyPKCS1 :%s", k)
+			}
+		}
+	}
+	t.Log("success EncodeStrict")
+}
+
+func Test_GenerateJWKThumbprint(t *testing.T) {
+	testcases := []struct {
+		jwk        string
+		thumbprint string
+	}{
+		// RFC 7638 Section 3.1
+		{`{"kty":"RSA","n":"0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw","e":"AQAB","alg":"RS256","kid":"2011-04-29"}`, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"},
+		// RFC 8037 Appendix A.3
+		{`{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`, "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k"},
+		// RFC 7748 Section 6.1 Alice's public key
+		{`{"kty":"OKP","crv":"X25519","x":"hSDwCYkwp1R0i33ctD73Wg2_Og0mOBr066SpjqqbTmo"}`, "u809Vppx5ixWMOohxWr2aM3m5bD0LQ67g_GPmubQus4"},
+	}
+	for _, tc := range testcases {
+		encryptkey := &entity.EncryptKey{}
+		if err := DecodeJWK([]byte(tc.jwk), encryptkey); err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+		thumbprint, err := GenerateJWKThumbprint(encryptkey, crypto.SHA256)
+		if err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+		if thumbprint != tc.thumbprint {
+			t.Fatalf("failed GenerateJWKThumbprint %s %s", encryptkey.Keytype, thumbprint)
+		}
+	}
+	if _, err := GenerateJWKThumbprint(&entity.EncryptKey{}, crypto.SHA256); err == nil {
+		t.Fatal("failed GenerateJWKThumbprint without key")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	t.Log("success GenerateJWKThumbprint")
+}
@@ -0,0 +1,82 @@
+package signer
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"errors"
+	"math/big"
+
+	"github.com/howood/cryptotools/internal/entity"
+)
+
+// SignerEcdsa represents Ecdsa signature struct
+type SignerEcdsa struct {
+	ecdsakey *entity.EcdsaKey
+	format   entity.EcdsaSignatureFormat
+	hashType crypto.Hash
+}
+
+// NewSignerEcdsa create SignerEcdsa struct
+func NewSignerEcdsa(ecdsakey *entity.EcdsaKey, format entity.EcdsaSignatureFormat, hashType crypto.Hash) (*SignerEcdsa, error) {
+	switch format {
+	case entity.EcdsaSignatureFormatASN1, entity.EcdsaSignatureFormatRaw:
+	default:
+		return nil, errors.New("Invalid ECDSA signature format")
+	}
+	if _, err := digest(hashType, nil); err != nil {
+		return nil, err
+	}
+	return &SignerEcdsa{
+		ecdsakey: ecdsakey,
+		format:   format,
+		hashType: hashType,
+	}, nil
+}
+
+// Sign signs a input data
+func (se *SignerEcdsa) Sign(input []byte) ([]byte, error) {
+	hashed, err := digest(se.hashType, input)
+	if err != nil {
+		return nil, err
+	}
+	if se.format == entity.EcdsaSignatureFormatASN1 {
+		return ecdsa.SignASN1(rand.Reader, se.ecdsakey.PrivateKey, hashed)
+	}
+	r, s, err := ecdsa.Sign(rand.Reader, se.ecdsakey.PrivateKey, hashed)
+	if err != nil {
+		return nil, err
+	}
+	size := se.scalarSize()
+	signature := make([]byte, 2*size)
+	r.FillBytes(signature[:size])
+	s.FillBytes(signature[size:])
+	return signature, nil
+}
+
+// Verify verifies a signature of a input data
+func (se *SignerEcdsa) Verify(input, signature []byte) error {
+	hashed, err := digest(se.hashType, input)
+	if err != nil {
+		return err
+	}
+	var ok bool
+	if se.format == entity.EcdsaSignatureFormatASN1 {
+		ok = ecdsa.VerifyASN1(se.ecdsakey.PublicKey, hashed, signature)
+	} else {
+		size := se.scalarSize()
+		if len(signature) == 2*size {
+			r := new(big.Int).SetBytes(signature[:size])
+			s := new(big.Int).SetBytes(signature[size:])
+			ok = ecdsa.Verify(se.ecdsakey.PublicKey, hashed, r, s)
+		}
+	}
+	if !ok {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func (se *SignerEcdsa) scalarSize() int {
+	return (se.ecdsakey.PublicKey.Curve.Params().N.BitLen() + 7) / 8
+}
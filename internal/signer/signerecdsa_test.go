@@ -0,0 +1,54 @@
+package signer
+
+import (
+	"crypto"
+	"errors"
+	"testing"
+
+	"github.com/howood/cryptotools/internal/entity"
+	"github.com/howood/cryptotools/internal/generator"
+)
+
+func Test_SignerEcdsa(t *testing.T) {
+	testdata := []byte("testdata")
+	for _, bits := range []int{256, 384, 521} {
+		ecdsakey := entity.EcdsaKey{}
+		var err error
+		if ecdsakey.PrivateKey, ecdsakey.PublicKey, err = generator.GenerateEcdsaKeys(bits); err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+		publickey := entity.EcdsaKey{PublicKey: ecdsakey.PublicKey}
+		for _, format := range []entity.EcdsaSignatureFormat{entity.EcdsaSignatureFormatASN1, entity.EcdsaSignatureFormatRaw} {
+			signerecdsa, err := NewSignerEcdsa(&ecdsakey, format, crypto.SHA256)
+			if err != nil {
+				t.Fatalf("failed test %#v", err)
+			}
+			signature, err := signerecdsa.Sign(testdata)
+			if err != nil {
+				t.Fatalf("failed test %#v", err)
+			}
+			if format == entity.EcdsaSignatureFormatRaw && len(signature) != 2*((bits+7)/8) {
+				t.Fatalf("failed raw signature size %d", len(signature))
+			}
+			verifier, err := NewSignerEcdsa(&publickey, format, crypto.SHA256)
+			if err != nil {
+				t.Fatalf("failed test %#v", err)
+			}
+			if err := verifier.Verify(testdata, signature); err != nil {
+				t.Fatalf("failed Verify %d %s %#v", bits, format, err)
+			}
+			if err := verifier.Verify([]byte("testdata2"), signature); !errors.Is(err, ErrInvalidSignature) {
+				t.Fatalf("failed Verify other data %#v", err)
+			}
+			if err := verifier.Verify(testdata, signature[1:]); !errors.Is(err, ErrInvalidSignature) {
+				t.Fatalf("failed Verify short signature %#v", err)
+			}
+		}
+	}
+	if _, err := NewSignerEcdsa(&entity.EcdsaKey{}, "der", crypto.SHA256); err == nil {
+		t.Fatal("failed NewSignerEcdsa ")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	t.Log("success SignerEcdsa")
+}
@@ -0,0 +1,32 @@
+package signer
+
+import (
+	"crypto/ed25519"
+
+	"github.com/howood/cryptotools/internal/entity"
+)
+
+// SignerEd25519 represents pure Ed25519 signature struct
+type SignerEd25519 struct {
+	ed25519key *entity.Ed25519Key
+}
+
+// NewSignerEd25519 create SignerEd25519 struct
+func NewSignerEd25519(ed25519key *entity.Ed25519Key) *SignerEd25519 {
+	return &SignerEd25519{
+		ed25519key: ed25519key,
+	}
+}
+
+// Sign signs a input data
+func (se *SignerEd25519) Sign(input []byte) ([]byte, error) {
+	return ed25519.Sign(*se.ed25519key.PrivateKey, input), nil
+}
+
+// Verify verifies a signature of a input data
+func (se *SignerEd25519) Verify(input, signature []byte) error {
+	if !ed25519.Verify(*se.ed25519key.PublicKey, input, signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
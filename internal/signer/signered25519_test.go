@@ -0,0 +1,38 @@
+package signer
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/howood/cryptotools/internal/entity"
+)
+
+func Test_SignerEd25519(t *testing.T) {
+	// RFC 8032 section 7.1 TEST 1
+	seed, _ := hex.DecodeString("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60")
+	checksignature, _ := hex.DecodeString("e5564300c360ac729086e2cc806e828a84877f1eb8e5d974d873e065224901555fb8821590a33bacc61e39701cf9b46bd25bf5f0595bbe24655141438e7a100b")
+	privatekey := ed25519.NewKeyFromSeed(seed)
+	publickey := privatekey.Public().(ed25519.PublicKey)
+	ed25519key := entity.Ed25519Key{PrivateKey: &privatekey, PublicKey: &publickey}
+
+	signered25519 := NewSignerEd25519(&ed25519key)
+	signature, err := signered25519.Sign([]byte{})
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if hex.EncodeToString(signature) != hex.EncodeToString(checksignature) {
+		t.Fatalf("failed Sign %x", signature)
+	}
+	verifier := NewSignerEd25519(&entity.Ed25519Key{PublicKey: &publickey})
+	if err := verifier.Verify([]byte{}, signature); err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if err := verifier.Verify([]byte("testdata"), signature); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("failed Verify other data %#v", err)
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	t.Log("success SignerEd25519")
+}
@@ -0,0 +1,63 @@
+package signer
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+
+	"github.com/howood/cryptotools/internal/entity"
+)
+
+// SignerRsa represents Rsa signature struct
+type SignerRsa struct {
+	rsakey   *entity.RsaKey
+	scheme   entity.RsaSignatureScheme
+	hashType crypto.Hash
+}
+
+// NewSignerRsa create SignerRsa struct
+func NewSignerRsa(rsakey *entity.RsaKey, scheme entity.RsaSignatureScheme, hashType crypto.Hash) (*SignerRsa, error) {
+	switch scheme {
+	case entity.RsaSignatureSchemePSS, entity.RsaSignatureSchemePKCS1v15:
+	default:
+		return nil, errors.New("Invalid RSA signature scheme")
+	}
+	if _, err := digest(hashType, nil); err != nil {
+		return nil, err
+	}
+	return &SignerRsa{
+		rsakey:   rsakey,
+		scheme:   scheme,
+		hashType: hashType,
+	}, nil
+}
+
+// Sign signs a input data
+func (sr *SignerRsa) Sign(input []byte) ([]byte, error) {
+	hashed, err := digest(sr.hashType, input)
+	if err != nil {
+		return nil, err
+	}
+	if sr.scheme == entity.RsaSignatureSchemePSS {
+		return rsa.SignPSS(rand.Reader, sr.rsakey.PrivateKey, sr.hashType, hashed, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	}
+	return rsa.SignPKCS1v15(rand.Reader, sr.rsakey.PrivateKey, sr.hashType, hashed)
+}
+
+// Verify verifies a signature of a input data
+func (sr *SignerRsa) Verify(input, signature []byte) error {
+	hashed, err := digest(sr.hashType, input)
+	if err != nil {
+		return err
+	}
+	if sr.scheme == entity.RsaSignatureSchemePSS {
+		err = rsa.VerifyPSS(sr.rsakey.PublicKey, sr.hashType, hashed, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto})
+	} else {
+		err = rsa.VerifyPKCS1v15(sr.rsakey.PublicKey, sr.hashType, hashed, signature)
+	}
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	return nil
+}
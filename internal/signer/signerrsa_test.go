@@ -0,0 +1,65 @@
+package signer
+
+import (
+	"crypto"
+	"errors"
+	"testing"
+
+	"github.com/howood/cryptotools/internal/entity"
+	"github.com/howood/cryptotools/internal/generator"
+)
+
+func Test_SignerRsa(t *testing.T) {
+	testdata := []byte("testdata")
+	rsakey := entity.RsaKey{}
+	var err error
+	if rsakey.PrivateKey, rsakey.PublicKey, err = generator.GenerateRsaKeys(2048); err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	publickey := entity.RsaKey{PublicKey: rsakey.PublicKey}
+	for _, scheme := range []entity.RsaSignatureScheme{entity.RsaSignatureSchemePSS, entity.RsaSignatureSchemePKCS1v15} {
+		for _, hashType := range []crypto.Hash{crypto.SHA256, crypto.SHA384, crypto.SHA512} {
+			signerrsa, err := NewSignerRsa(&rsakey, scheme, hashType)
+			if err != nil {
+				t.Fatalf("failed test %#v", err)
+			}
+			signature, err := signerrsa.Sign(testdata)
+			if err != nil {
+				t.Fatalf("failed test %#v", err)
+			}
+			verifier, err := NewSignerRsa(&publickey, scheme, hashType)
+			if err != nil {
+				t.Fatalf("failed test %#v", err)
+			}
+			if err := verifier.Verify(testdata, signature); err != nil {
+				t.Fatalf("failed Verify %s %s %#v", scheme, hashType, err)
+			}
+			if err := verifier.Verify([]byte("testdata2"), signature); !errors.Is(err, ErrInvalidSignature) {
+				t.Fatalf("failed Verify other data %#v", err)
+			}
+		}
+	}
+
+	pss, _ := NewSignerRsa(&rsakey, entity.RsaSignatureSchemePSS, crypto.SHA256)
+	pkcs1v15, _ := NewSignerRsa(&rsakey, entity.RsaSignatureSchemePKCS1v15, crypto.SHA256)
+	signature, err := pss.Sign(testdata)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if err := pkcs1v15.Verify(testdata, signature); err == nil {
+		t.Fatal("failed Verify PSS signature with PKCS1v15")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	if _, err := NewSignerRsa(&rsakey, "x931", crypto.SHA256); err == nil {
+		t.Fatal("failed NewSignerRsa ")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	if _, err := NewSignerRsa(&rsakey, entity.RsaSignatureSchemePSS, crypto.MD5); err == nil {
+		t.Fatal("failed NewSignerRsa ")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	t.Log("success SignerRsa")
+}
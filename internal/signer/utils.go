@@ -0,0 +1,29 @@
+package signer
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"hash"
+)
+
+// ErrInvalidSignature is returned when a signature does not match the data
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// digest hashes data with hashType which must be SHA-256, SHA-384 or SHA-512
+func digest(hashType crypto.Hash, data []byte) ([]byte, error) {
+	var h hash.Hash
+	switch hashType {
+	case crypto.SHA256:
+		h = sha256.New()
+	case crypto.SHA384:
+		h = sha512.New384()
+	case crypto.SHA512:
+		h = sha512.New()
+	default:
+		return nil, errors.New("Invalid signature hash")
+	}
+	h.Write(data)
+	return h.Sum(nil), nil
+}
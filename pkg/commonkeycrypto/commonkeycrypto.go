@@ -1,14 +1,128 @@
 package commonkeycrypto
 
 import (
+	"errors"
+	"io"
+
 	"github.com/howood/cryptotools/internal/encrypter"
+	"github.com/howood/cryptotools/internal/entity"
+	"github.com/howood/cryptotools/internal/generator"
+	"github.com/howood/cryptotools/internal/parser"
 	"github.com/segmentio/ksuid"
 )
 
-// CommonKeyCrypto represents CommonKeyCrypto struct
+// CipherMode is CommonKeyCrypto cipher mode
+type CipherMode string
+
+const (
+	// CipherModeAesOfb is AES-OFB mode using Identifier as IV. It is kept for compatibility.
+	// It is the only mode whose output is raw ciphertext without the versioned envelope,
+	// so it is neither authenticated nor dispatched by the envelope header on decryption
+	CipherModeAesOfb CipherMode = "aes-ofb"
+	// CipherModeAesGcm is AES-GCM authenticated mode with a random nonce per message
+	CipherModeAesGcm CipherMode = "aes-gcm"
+	// CipherModeXChaCha20Poly1305 is XChaCha20-Poly1305 authenticated mode with a random 24 bytes nonce per message
+	CipherModeXChaCha20Poly1305 CipherMode = "xchacha20-poly1305"
+	// CipherModeAesSiv is deterministic AES-SIV mode giving the same output for the same input and associated data.
+	// It is only used by NewDeterministicCommonKeyCrypto
+	CipherModeAesSiv CipherMode = "aes-siv"
+)
+
+// KeyDerivation is passphrase key derivation function
+type KeyDerivation string
+
+const (
+	// KeyDerivationArgon2id is Argon2id key derivation
+	KeyDerivationArgon2id KeyDerivation = "argon2id"
+	// KeyDerivationScrypt is scrypt key derivation
+	KeyDerivationScrypt KeyDerivation = "scrypt"
+	// KeyDerivationPBKDF2 is PBKDF2 with HMAC-SHA256 key derivation
+	KeyDerivationPBKDF2 KeyDerivation = "pbkdf2-sha256"
+)
+
+// Encoding is text encoding of ciphertext used by Encrypt and Decrypt
+type Encoding entity.Encoding
+
+const (
+	// EncodingBase64Std is standard base64 encoding. It is the default
+	EncodingBase64Std Encoding = Encoding(entity.EncodingBase64Std)
+	// EncodingBase64URL is URL-safe base64 encoding with padding
+	EncodingBase64URL Encoding = Encoding(entity.EncodingBase64URL)
+	// EncodingBase64RawURL is URL-safe base64 encoding without padding
+	EncodingBase64RawURL Encoding = Encoding(entity.EncodingBase64RawURL)
+	// EncodingHex is hex encoding
+	EncodingHex Encoding = Encoding(entity.EncodingHex)
+	// EncodingBase32 is base32 encoding
+	EncodingBase32 Encoding = Encoding(entity.EncodingBase32)
+	// EncodingRaw is no encoding, the ciphertext bytes are returned as string
+	EncodingRaw Encoding = Encoding(entity.EncodingRaw)
+)
+
+const (
+	errorInvalidCipherMode      = "Invalid cipher mode"
+	errorInvalidCipherAlgorithm = "Invalid cipher algorithm"
+	errorInvalidKeyDerivation   = "Invalid key derivation"
+	errorKeyIDMismatch          = "Key ID mismatch"
+	errorKeyDerivationMismatch  = "Key derivation mismatch between ciphertext and CommonKeyCrypto"
+)
+
+const (
+	envelopeVersion = 1
+	derivedKeySize  = 32
+)
+
+// cipherAlgorithms maps authenticated cipher modes to the algorithm ID stored in the envelope
+var cipherAlgorithms = map[CipherMode]entity.CipherAlgorithm{
+	CipherModeAesGcm:            entity.CipherAlgorithmAesGcm,
+	CipherModeXChaCha20Poly1305: entity.CipherAlgorithmXChaCha20Poly1305,
+	CipherModeAesSiv:            entity.CipherAlgorithmAesSiv,
+}
+
+// keyDerivationAlgorithms maps key derivation functions to the algorithm ID stored in the envelope
+var keyDerivationAlgorithms = map[KeyDerivation]entity.KeyDerivationAlgorithm{
+	KeyDerivationArgon2id: entity.KeyDerivationArgon2id,
+	KeyDerivationScrypt:   entity.KeyDerivationScrypt,
+	KeyDerivationPBKDF2:   entity.KeyDerivationPBKDF2SHA256,
+}
+
+var (
+	// ErrAuthenticationFailed is returned when decrypting data that has been modified
+	ErrAuthenticationFailed = encrypter.ErrAuthenticationFailed
+	// ErrInvalidEnvelope is returned when decrypting data that is not an envelope
+	ErrInvalidEnvelope = parser.ErrInvalidEnvelope
+)
+
+// UnsupportedVersionError is returned when decrypting an envelope with an unknown version
+type UnsupportedVersionError = parser.UnsupportedEnvelopeVersionError
+
+type aeadEncrypter interface {
+	Encrypt(input []byte) ([]byte, error)
+	Decrypt(input []byte) ([]byte, error)
+	EncryptWithAAD(input, additionalData []byte) ([]byte, error)
+	DecryptWithAAD(input, additionalData []byte) ([]byte, error)
+	NonceSize() int
+}
+
+type aeadStreamEncrypter interface {
+	aeadEncrypter
+	NewStreamWriter(salt, additionalData []byte, w io.Writer) (*encrypter.StreamWriter, error)
+	NewStreamReader(salt, additionalData []byte, r io.Reader) (*encrypter.StreamReader, error)
+}
+
+// CommonKeyCrypto represents CommonKeyCrypto struct.
+// Authenticated modes output a versioned envelope holding the cipher algorithm, key ID, nonce and ciphertext,
+// and the envelope header is authenticated with the ciphertext. AES-OFB mode outputs raw ciphertext.
 type CommonKeyCrypto struct {
-	Identifier string
-	encrypter  *encrypter.CryptoAes
+	Identifier    string
+	keyID         string
+	mode          CipherMode
+	commonKey     []byte
+	passphrase    []byte
+	keyDerivation KeyDerivation
+	encrypter     *encrypter.CryptoAes
+	encrypterAead aeadEncrypter
+	keyring       *Keyring
+	encoding      Encoding
 }
 
 // NewCommonKeyCrypto create CommonKeyCrypto struct
@@ -17,18 +131,346 @@ func NewCommonKeyCrypto(commonKey []byte) (*CommonKeyCrypto, error) {
 	cryptoaes, err := encrypter.NewCryptoAes(commonKey, []byte(identifier))
 	return &CommonKeyCrypto{
 		Identifier: identifier,
+		mode:       CipherModeAesOfb,
 		encrypter:  cryptoaes,
 	}, err
 }
 
-// Encrypt encrypts input data with commonkey encryption
+// NewCommonKeyCryptoWithIdentifier create CommonKeyCrypto struct with a persisted Identifier.
+// It rebuilds the AES-OFB encrypter so that data encrypted by another instance can be decrypted.
+func NewCommonKeyCryptoWithIdentifier(commonKey []byte, identifier string) (*CommonKeyCrypto, error) {
+	cryptoaes, err := encrypter.NewCryptoAes(commonKey, []byte(identifier))
+	if err != nil {
+		return nil, err
+	}
+	return &CommonKeyCrypto{
+		Identifier: identifier,
+		mode:       CipherModeAesOfb,
+		encrypter:  cryptoaes,
+	}, nil
+}
+
+// NewCommonKeyCryptoWithMode create CommonKeyCrypto struct with cipher mode
+func NewCommonKeyCryptoWithMode(commonKey []byte, mode CipherMode) (*CommonKeyCrypto, error) {
+	switch mode {
+	case CipherModeAesOfb:
+		return NewCommonKeyCrypto(commonKey)
+	case CipherModeAesSiv:
+		return nil, errors.New(errorInvalidCipherMode)
+	}
+	encrypterAead, err := newAeadEncrypter(mode, commonKey)
+	if err != nil {
+		return nil, err
+	}
+	return &CommonKeyCrypto{
+		Identifier:    getUUID(),
+		mode:          mode,
+		commonKey:     append([]byte{}, commonKey...),
+		encrypterAead: encrypterAead,
+	}, nil
+}
+
+// NewDeterministicCommonKeyCrypto create CommonKeyCrypto struct with deterministic AES-SIV mode.
+// The same input and associated data always give the same output so that encrypted values can be
+// looked up by equality, which also reveals which values are equal. commonKey must be 32, 48 or 64 bytes.
+// Use the randomized modes unless equality search is needed.
+func NewDeterministicCommonKeyCrypto(commonKey []byte) (*CommonKeyCrypto, error) {
+	encrypterAead, err := newAeadEncrypter(CipherModeAesSiv, commonKey)
+	if err != nil {
+		return nil, err
+	}
+	return &CommonKeyCrypto{
+		Identifier:    getUUID(),
+		mode:          CipherModeAesSiv,
+		commonKey:     append([]byte{}, commonKey...),
+		encrypterAead: encrypterAead,
+	}, nil
+}
+
+// NewCommonKeyCryptoWithPassphrase create CommonKeyCrypto struct with passphrase.
+// A key is derived for each message with a random salt, and the salt and key derivation
+// parameters are stored in the envelope so that only the passphrase is needed to decrypt.
+func NewCommonKeyCryptoWithPassphrase(passphrase []byte, mode CipherMode, keyDerivation KeyDerivation) (*CommonKeyCrypto, error) {
+	if _, ok := cipherAlgorithms[mode]; !ok || mode == CipherModeAesSiv {
+		return nil, errors.New(errorInvalidCipherMode)
+	}
+	if _, ok := keyDerivationAlgorithms[keyDerivation]; !ok {
+		return nil, errors.New(errorInvalidKeyDerivation)
+	}
+	return &CommonKeyCrypto{
+		Identifier:    getUUID(),
+		mode:          mode,
+		passphrase:    append([]byte{}, passphrase...),
+		keyDerivation: keyDerivation,
+	}, nil
+}
+
+// NewCommonKeyCryptoWithKeyring create CommonKeyCrypto struct with Keyring.
+// It encrypts with the primary key of the keyring, stamping its key ID on the output,
+// and decrypts with the key matching the stamped key ID.
+// The keyring must have a primary key, which cannot be removed once added.
+func NewCommonKeyCryptoWithKeyring(keyring *Keyring) (*CommonKeyCrypto, error) {
+	if keyring == nil {
+		return nil, errors.New(errorNoPrimaryKey)
+	}
+	if _, err := keyring.primaryKey(); err != nil {
+		return nil, err
+	}
+	return &CommonKeyCrypto{
+		Identifier: getUUID(),
+		keyring:    keyring,
+	}, nil
+}
+
+// Encrypt encrypts input data with commonkey encryption and encodes it with Encoding.
+// Key ID and keyring are checked when they are set, so it panics only when reading random data fails.
+// Use EncryptString to get the error instead.
 func (ck *CommonKeyCrypto) Encrypt(input string) string {
-	return ck.encrypter.EncryptWithBase64(input)
+	encoded, err := ck.EncryptString(input)
+	if err != nil {
+		panic(err)
+	}
+	return encoded
+}
+
+// EncryptString encrypts input data with commonkey encryption and encodes it with Encoding
+func (ck *CommonKeyCrypto) EncryptString(input string) (string, error) {
+	ciphertext, err := ck.EncryptBytes([]byte(input))
+	if err != nil {
+		return "", err
+	}
+	return parser.EncodeWithEncoding(ciphertext, entity.Encoding(ck.encoding))
+}
+
+// EncryptWithAAD encrypts input data and binds it to additionalData such as a record ID.
+// The same additionalData must be given to DecryptWithAAD. It is not supported in AES-OFB mode.
+func (ck *CommonKeyCrypto) EncryptWithAAD(input string, additionalData []byte) (string, error) {
+	ciphertext, err := ck.sealEnvelope([]byte(input), additionalData)
+	if err != nil {
+		return "", err
+	}
+	return parser.EncodeWithEncoding(ciphertext, entity.Encoding(ck.encoding))
+}
+
+// EncryptBytes encrypts binary input data with commonkey encryption without encoding
+func (ck *CommonKeyCrypto) EncryptBytes(input []byte) ([]byte, error) {
+	if ck.mode == CipherModeAesOfb {
+		return ck.encrypter.Encrypt(input), nil
+	}
+	return ck.sealEnvelope(input, nil)
 }
 
-// Decrypt decrypts input data with commonkey encryption
+// Decrypt decrypts input data encoded with Encoding with commonkey encryption.
+// Envelopes are decrypted with the cipher recorded in them.
 func (ck *CommonKeyCrypto) Decrypt(input string) (string, error) {
-	return ck.encrypter.DecryptWithBase64(input)
+	inputdecoded, err := parser.DecodeWithEncoding(input, entity.Encoding(ck.encoding))
+	if err != nil {
+		return "", err
+	}
+	decrypttext, err := ck.DecryptBytes(inputdecoded)
+	if err != nil {
+		return "", err
+	}
+	return string(decrypttext), nil
+}
+
+// DecryptWithAAD decrypts input data encrypted by EncryptWithAAD.
+// It returns ErrAuthenticationFailed when additionalData differs from the one used to encrypt.
+func (ck *CommonKeyCrypto) DecryptWithAAD(input string, additionalData []byte) (string, error) {
+	if ck.mode == CipherModeAesOfb {
+		return "", errors.New(errorInvalidCipherMode)
+	}
+	inputdecoded, err := parser.DecodeWithEncoding(input, entity.Encoding(ck.encoding))
+	if err != nil {
+		return "", err
+	}
+	decrypttext, err := ck.openEnvelope(inputdecoded, additionalData)
+	if err != nil {
+		return "", err
+	}
+	return string(decrypttext), nil
+}
+
+// DecryptBytes decrypts binary input data encrypted by EncryptBytes
+func (ck *CommonKeyCrypto) DecryptBytes(input []byte) ([]byte, error) {
+	if ck.mode == CipherModeAesOfb {
+		return ck.encrypter.Decrypt(input), nil
+	}
+	return ck.openEnvelope(input, nil)
+}
+
+// SetEncoding sets the encoding of ciphertext used by Encrypt and Decrypt. The default is EncodingBase64Std
+func (ck *CommonKeyCrypto) SetEncoding(encoding Encoding) error {
+	if err := parser.ValidateEncoding(entity.Encoding(encoding)); err != nil {
+		return err
+	}
+	ck.encoding = encoding
+	return nil
+}
+
+// SetKeyID sets the key ID stamped on the envelope by Encrypt and checked by Decrypt.
+// The key ID must be at most 255 bytes. It is not supported with Keyring, which stamps the key ID of the primary key.
+func (ck *CommonKeyCrypto) SetKeyID(keyID string) error {
+	if len(keyID) > maxKeyIDLength || ck.keyring != nil {
+		return errors.New(errorInvalidKeyID)
+	}
+	ck.keyID = keyID
+	return nil
+}
+
+// KeyID returns the key ID stamped on the envelope. With Keyring it returns the key ID of the primary key.
+func (ck *CommonKeyCrypto) KeyID() string {
+	if ck.keyring != nil {
+		return ck.keyring.Primary()
+	}
+	return ck.keyID
+}
+
+// Encoding returns the encoding of ciphertext
+func (ck *CommonKeyCrypto) Encoding() Encoding {
+	if ck.encoding == "" {
+		return EncodingBase64Std
+	}
+	return ck.encoding
+}
+
+// Mode returns cipher mode. With Keyring it returns the cipher mode of the primary key.
+func (ck *CommonKeyCrypto) Mode() CipherMode {
+	if ck.keyring != nil {
+		primary, err := ck.keyring.primaryKey()
+		if err != nil {
+			return ""
+		}
+		return primary.mode
+	}
+	return ck.mode
+}
+
+func (ck *CommonKeyCrypto) sealEnvelope(input, additionalData []byte) ([]byte, error) {
+	envelope, encrypterAead, err := ck.newEnvelope()
+	if err != nil {
+		return nil, err
+	}
+	envelopeAdditionalData, err := parser.EnvelopeAdditionalData(envelope, additionalData)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := encrypterAead.EncryptWithAAD(input, envelopeAdditionalData)
+	if err != nil {
+		return nil, err
+	}
+	noncesize := encrypterAead.NonceSize()
+	envelope.Nonce = ciphertext[:noncesize]
+	envelope.Ciphertext = ciphertext[noncesize:]
+	return parser.MarshalEnvelope(envelope)
+}
+
+// newEnvelope creates envelope header and the encrypter for it
+func (ck *CommonKeyCrypto) newEnvelope() (*entity.Envelope, aeadEncrypter, error) {
+	if ck.keyring != nil {
+		primary, err := ck.keyring.primaryKey()
+		if err != nil {
+			return nil, nil, err
+		}
+		return primary.newEnvelope()
+	}
+	if ck.mode == CipherModeAesOfb {
+		return nil, nil, errors.New(errorInvalidCipherMode)
+	}
+	envelope := &entity.Envelope{
+		Version:   envelopeVersion,
+		Algorithm: cipherAlgorithms[ck.mode],
+		KeyID:     ck.keyID,
+	}
+	if ck.passphrase == nil {
+		return envelope, ck.encrypterAead, nil
+	}
+	var err error
+	if envelope.KeyDerivation, err = generator.GenerateKeyDerivation(keyDerivationAlgorithms[ck.keyDerivation]); err != nil {
+		return nil, nil, err
+	}
+	encrypterAead, err := ck.encrypterForEnvelope(envelope)
+	if err != nil {
+		return nil, nil, err
+	}
+	return envelope, encrypterAead, nil
+}
+
+func (ck *CommonKeyCrypto) openEnvelope(input, additionalData []byte) ([]byte, error) {
+	envelope, err := parser.UnmarshalEnvelope(input)
+	if err != nil {
+		return nil, err
+	}
+	encrypterAead, err := ck.encrypterForEnvelope(envelope)
+	if err != nil {
+		return nil, err
+	}
+	envelopeAdditionalData, err := parser.EnvelopeAdditionalData(envelope, additionalData)
+	if err != nil {
+		return nil, err
+	}
+	return encrypterAead.DecryptWithAAD(append(envelope.Nonce, envelope.Ciphertext...), envelopeAdditionalData)
+}
+
+func (ck *CommonKeyCrypto) encrypterForEnvelope(envelope *entity.Envelope) (aeadEncrypter, error) {
+	if ck.keyring != nil {
+		key, err := ck.keyring.key(envelope.KeyID)
+		if err != nil {
+			return nil, err
+		}
+		return key.encrypterForEnvelope(envelope)
+	}
+	if envelope.KeyID != ck.keyID {
+		return nil, errors.New(errorKeyIDMismatch)
+	}
+	var mode CipherMode
+	for m, id := range cipherAlgorithms {
+		if id == envelope.Algorithm {
+			mode = m
+		}
+	}
+	if mode == "" || (mode == CipherModeAesSiv) != (ck.mode == CipherModeAesSiv) {
+		return nil, errors.New(errorInvalidCipherAlgorithm)
+	}
+	if (envelope.KeyDerivation != nil) != (ck.passphrase != nil) {
+		return nil, errors.New(errorKeyDerivationMismatch)
+	}
+	if envelope.KeyDerivation != nil {
+		commonKey, err := generator.DeriveCommonKey(ck.passphrase, envelope.KeyDerivation, derivedKeySize)
+		if err != nil {
+			return nil, err
+		}
+		return newAeadEncrypter(mode, commonKey)
+	}
+	if mode == ck.mode {
+		return ck.encrypterAead, nil
+	}
+	return newAeadEncrypter(mode, ck.commonKey)
+}
+
+func newAeadEncrypter(mode CipherMode, commonKey []byte) (aeadEncrypter, error) {
+	switch mode {
+	case CipherModeAesGcm:
+		cryptoaesgcm, err := encrypter.NewCryptoAesGcm(commonKey)
+		if err != nil {
+			return nil, err
+		}
+		return cryptoaesgcm, nil
+	case CipherModeXChaCha20Poly1305:
+		cryptoxchacha, err := encrypter.NewCryptoXChaCha20Poly1305(commonKey)
+		if err != nil {
+			return nil, err
+		}
+		return cryptoxchacha, nil
+	case CipherModeAesSiv:
+		cryptoaessiv, err := encrypter.NewCryptoAesSiv(commonKey)
+		if err != nil {
+			return nil, err
+		}
+		return cryptoaessiv, nil
+	default:
+		return nil, errors.New(errorInvalidCipherMode)
+	}
 }
 
 func getUUID() string {
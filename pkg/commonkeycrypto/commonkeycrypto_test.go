@@ -1,8 +1,14 @@
 package commonkeycrypto
 
 import (
+	"bytes"
+	"encoding/base64"
+	"errors"
 	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/howood/cryptotools/internal/parser"
 )
 
 func Test_CommonKeyCrypto(t *testing.T) {
@@ -26,3 +32,515 @@ func Test_CommonKeyCrypto(t *testing.T) {
 	}
 	t.Log("success CommonKeyCrypto")
 }
+
+func Test_CommonKeyCryptoAesGcm(t *testing.T) {
+	testdata := `
+{
+    "message": "ok",
+    "message2": ["ng", "ng2"]
+}
+`
+	cc, err := NewCommonKeyCryptoWithMode([]byte("passw0rdpassw0rdpassw0rdpassw0rd"), CipherModeAesGcm)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	encryptdata := cc.Encrypt(testdata)
+	if encryptdata == cc.Encrypt(testdata) {
+		t.Fatal("failed CommonKeyCryptoAesGcm nonce is reused")
+	}
+	decryptdata, err := cc.Decrypt(encryptdata)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if reflect.DeepEqual([]byte(decryptdata), []byte(testdata)) == false {
+		t.Fatal("failed CommonKeyCryptoAesGcm ")
+	}
+
+	tampered, _ := base64.StdEncoding.DecodeString(encryptdata)
+	tampered[len(tampered)/2] ^= 0x01
+	if _, err := cc.Decrypt(base64.StdEncoding.EncodeToString(tampered)); !errors.Is(err, ErrAuthenticationFailed) {
+		t.Fatalf("failed Decrypt tampered data %#v", err)
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+
+	if _, err := NewCommonKeyCryptoWithMode([]byte("sssss"), CipherModeAesGcm); err == nil {
+		t.Fatal("failed NewCommonKeyCryptoWithMode ")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	if _, err := NewCommonKeyCryptoWithMode([]byte("passw0rdpassw0rdpassw0rdpassw0rd"), CipherMode("unknown")); err == nil {
+		t.Fatal("failed NewCommonKeyCryptoWithMode ")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	t.Log("success CommonKeyCryptoAesGcm")
+}
+
+func Test_CommonKeyCryptoXChaCha20Poly1305(t *testing.T) {
+	commonKey := []byte("passw0rdpassw0rdpassw0rdpassw0rd")
+	testdata := `
+{
+    "message": "ok",
+    "message2": ["ng", "ng2"]
+}
+`
+	cc, err := NewCommonKeyCryptoWithMode(commonKey, CipherModeXChaCha20Poly1305)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	encryptdata := cc.Encrypt(testdata)
+	decryptdata, err := cc.Decrypt(encryptdata)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if reflect.DeepEqual([]byte(decryptdata), []byte(testdata)) == false {
+		t.Fatal("failed CommonKeyCryptoXChaCha20Poly1305 ")
+	}
+
+	ccgcm, err := NewCommonKeyCryptoWithMode(commonKey, CipherModeAesGcm)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	decryptdata, err = ccgcm.Decrypt(encryptdata)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if reflect.DeepEqual([]byte(decryptdata), []byte(testdata)) == false {
+		t.Fatal("failed CommonKeyCryptoXChaCha20Poly1305 with recorded cipher")
+	}
+
+	unknown, _ := base64.StdEncoding.DecodeString(encryptdata)
+	unknown[2] = 0xff
+	if _, err := cc.Decrypt(base64.StdEncoding.EncodeToString(unknown)); err == nil {
+		t.Fatal("failed Decrypt unknown cipher")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	if _, err := cc.Decrypt(""); err == nil {
+		t.Fatal("failed Decrypt empty data")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	if _, err := NewCommonKeyCryptoWithMode([]byte("passw0rdpassw0rd"), CipherModeXChaCha20Poly1305); err == nil {
+		t.Fatal("failed NewCommonKeyCryptoWithMode ")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	t.Log("success CommonKeyCryptoXChaCha20Poly1305")
+}
+
+func Test_CommonKeyCryptoWithIdentifier(t *testing.T) {
+	commonKey := []byte("passw0rdpassw0rdpassw0rdpassw0rd")
+	testdata := `
+{
+    "message": "ok",
+    "message2": ["ng", "ng2"]
+}
+`
+	cc, err := NewCommonKeyCrypto(commonKey)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	encryptdata := cc.Encrypt(testdata)
+
+	ccwi, err := NewCommonKeyCryptoWithIdentifier(commonKey, cc.Identifier)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	decryptdata, err := ccwi.Decrypt(encryptdata)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if reflect.DeepEqual([]byte(decryptdata), []byte(testdata)) == false {
+		t.Fatal("failed CommonKeyCryptoWithIdentifier ")
+	}
+	if ccwi.Encrypt(testdata) != encryptdata {
+		t.Fatal("failed CommonKeyCryptoWithIdentifier encrypt")
+	}
+
+	if _, err := NewCommonKeyCryptoWithIdentifier(commonKey, "short"); err == nil {
+		t.Fatal("failed NewCommonKeyCryptoWithIdentifier ")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	t.Log("success CommonKeyCryptoWithIdentifier")
+}
+
+func Test_CommonKeyCryptoEnvelope(t *testing.T) {
+	commonKey := []byte("passw0rdpassw0rdpassw0rdpassw0rd")
+	testdata := "testdata"
+	cc, err := NewCommonKeyCryptoWithMode(commonKey, CipherModeAesGcm)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if err := cc.SetKeyID("key-2023"); err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if err := cc.SetKeyID(strings.Repeat("k", 256)); err == nil {
+		t.Fatal("failed SetKeyID with too long key ID")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	if cc.KeyID() != "key-2023" {
+		t.Fatalf("failed KeyID %s", cc.KeyID())
+	}
+	encryptdata := cc.Encrypt(testdata)
+	envelope, _ := base64.StdEncoding.DecodeString(encryptdata)
+	if reflect.DeepEqual(envelope[:12], append([]byte{0xc7, 0x01, 0x01, 0x08}, "key-2023"...)) == false {
+		t.Fatalf("failed CommonKeyCryptoEnvelope header %v", envelope[:12])
+	}
+	decryptdata, err := cc.Decrypt(encryptdata)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if decryptdata != testdata {
+		t.Fatal("failed CommonKeyCryptoEnvelope ")
+	}
+
+	if _, err := cc.DecryptWithAAD(cc.Encrypt(testdata), []byte("record")); !errors.Is(err, ErrAuthenticationFailed) {
+		t.Fatalf("failed Decrypt with additionalData %#v", err)
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+
+	envelope[1] = 0x7f
+	var versionErr *UnsupportedVersionError
+	if _, err := cc.Decrypt(base64.StdEncoding.EncodeToString(envelope)); !errors.As(err, &versionErr) {
+		t.Fatalf("failed Decrypt unknown version %#v", err)
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	if _, err := cc.Decrypt(base64.StdEncoding.EncodeToString([]byte("not envelope"))); !errors.Is(err, ErrInvalidEnvelope) {
+		t.Fatalf("failed Decrypt invalid envelope %#v", err)
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+
+	cc2, err := NewCommonKeyCryptoWithMode(commonKey, CipherModeAesGcm)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if _, err := cc2.Decrypt(encryptdata); err == nil {
+		t.Fatal("failed Decrypt with different KeyID")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	t.Log("success CommonKeyCryptoEnvelope")
+}
+
+func Test_CommonKeyCryptoOfbWithoutEnvelope(t *testing.T) {
+	commonKey := []byte("passw0rdpassw0rdpassw0rdpassw0rd")
+	testdata := []byte("testdata")
+	cc, err := NewCommonKeyCrypto(commonKey)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	encrypted, err := cc.EncryptBytes(testdata)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if len(encrypted) != len(testdata) {
+		t.Fatalf("failed CommonKeyCryptoOfbWithoutEnvelope length %d", len(encrypted))
+	}
+	ccgcm, err := NewCommonKeyCryptoWithMode(commonKey, CipherModeAesGcm)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	envelope, err := ccgcm.EncryptBytes(testdata)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if decrypted, err := cc.DecryptBytes(envelope); err != nil || reflect.DeepEqual(decrypted, testdata) {
+		t.Fatalf("failed Decrypt envelope in AES-OFB mode %#v", err)
+	}
+	if _, err := cc.EncryptWithAAD(string(testdata), []byte("record")); err == nil {
+		t.Fatal("failed EncryptWithAAD in AES-OFB mode")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	t.Log("success CommonKeyCryptoOfbWithoutEnvelope")
+}
+
+func Test_CommonKeyCryptoWithPassphrase(t *testing.T) {
+	testdata := `
+{
+    "message": "ok",
+    "message2": ["ng", "ng2"]
+}
+`
+	passphrase := []byte("correct horse battery staple")
+	for _, keyDerivation := range []KeyDerivation{KeyDerivationArgon2id, KeyDerivationScrypt, KeyDerivationPBKDF2} {
+		cc, err := NewCommonKeyCryptoWithPassphrase(passphrase, CipherModeAesGcm, keyDerivation)
+		if err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+		encryptdata := cc.Encrypt(testdata)
+
+		ccother, err := NewCommonKeyCryptoWithPassphrase(passphrase, CipherModeXChaCha20Poly1305, KeyDerivationArgon2id)
+		if err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+		decryptdata, err := ccother.Decrypt(encryptdata)
+		if err != nil {
+			t.Fatalf("failed test %s %#v", keyDerivation, err)
+		}
+		if reflect.DeepEqual([]byte(decryptdata), []byte(testdata)) == false {
+			t.Fatalf("failed CommonKeyCryptoWithPassphrase %s", keyDerivation)
+		}
+
+		ccwrong, err := NewCommonKeyCryptoWithPassphrase([]byte("wrong"), CipherModeAesGcm, keyDerivation)
+		if err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+		if _, err := ccwrong.Decrypt(encryptdata); !errors.Is(err, ErrAuthenticationFailed) {
+			t.Fatalf("failed Decrypt with wrong passphrase %#v", err)
+		} else {
+			t.Logf("failed test %#v", err)
+		}
+	}
+
+	cckey, err := NewCommonKeyCryptoWithMode([]byte("passw0rdpassw0rdpassw0rdpassw0rd"), CipherModeAesGcm)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	cc, err := NewCommonKeyCryptoWithPassphrase(passphrase, CipherModeAesGcm, KeyDerivationScrypt)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if _, err := cckey.Decrypt(cc.Encrypt(testdata)); err == nil {
+		t.Fatal("failed Decrypt passphrase ciphertext with common key")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	if _, err := cc.Decrypt(cckey.Encrypt(testdata)); err == nil {
+		t.Fatal("failed Decrypt common key ciphertext with passphrase")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	if _, err := NewCommonKeyCryptoWithPassphrase(passphrase, CipherModeAesOfb, KeyDerivationScrypt); err == nil {
+		t.Fatal("failed NewCommonKeyCryptoWithPassphrase ")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	if _, err := NewCommonKeyCryptoWithPassphrase(passphrase, CipherModeAesGcm, KeyDerivation("md5")); err == nil {
+		t.Fatal("failed NewCommonKeyCryptoWithPassphrase ")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	t.Log("success CommonKeyCryptoWithPassphrase")
+}
+
+func Test_CommonKeyCryptoWithOversizedKeyDerivation(t *testing.T) {
+	passphrase := []byte("correct horse battery staple")
+	cc, err := NewCommonKeyCryptoWithPassphrase(passphrase, CipherModeAesGcm, KeyDerivationArgon2id)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	encrypted, err := cc.EncryptBytes([]byte("testdata"))
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	envelope, err := parser.UnmarshalEnvelope(encrypted)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	// deriving with these parameters would need terabytes of memory or hours,
+	// so they must be rejected before the key derivation runs
+	envelope.KeyDerivation.Memory = 0xffffffff
+	forged, err := parser.MarshalEnvelope(envelope)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if _, err := cc.DecryptBytes(forged); err == nil || errors.Is(err, ErrAuthenticationFailed) {
+		t.Fatalf("failed Decrypt with oversized memory %#v", err)
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	envelope.KeyDerivation.Memory = 64 * 1024
+	envelope.KeyDerivation.Iterations = 0xffffffff
+	if forged, err = parser.MarshalEnvelope(envelope); err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if _, err := cc.DecryptBytes(forged); err == nil || errors.Is(err, ErrAuthenticationFailed) {
+		t.Fatalf("failed Decrypt with oversized iterations %#v", err)
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	t.Log("success CommonKeyCryptoWithOversizedKeyDerivation")
+}
+
+func Test_CommonKeyCryptoWithAAD(t *testing.T) {
+	testdata := "testdata"
+	commonKey := []byte("passw0rdpassw0rdpassw0rdpassw0rd")
+	for _, mode := range []CipherMode{CipherModeAesGcm, CipherModeXChaCha20Poly1305} {
+		cc, err := NewCommonKeyCryptoWithMode(commonKey, mode)
+		if err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+		encrypted, err := cc.EncryptWithAAD(testdata, []byte("user:1"))
+		if err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+		decrypted, err := cc.DecryptWithAAD(encrypted, []byte("user:1"))
+		if err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+		if decrypted != testdata {
+			t.Fatalf("failed CommonKeyCryptoWithAAD %s", mode)
+		}
+		if _, err := cc.DecryptWithAAD(encrypted, []byte("user:2")); !errors.Is(err, ErrAuthenticationFailed) {
+			t.Fatalf("failed DecryptWithAAD with other additional data %#v", err)
+		} else {
+			t.Logf("failed test %#v", err)
+		}
+		if _, err := cc.Decrypt(encrypted); !errors.Is(err, ErrAuthenticationFailed) {
+			t.Fatalf("failed Decrypt without additional data %#v", err)
+		} else {
+			t.Logf("failed test %#v", err)
+		}
+	}
+
+	ccofb, err := NewCommonKeyCrypto(commonKey)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if _, err := ccofb.EncryptWithAAD(testdata, []byte("user:1")); err == nil {
+		t.Fatal("failed EncryptWithAAD with aes-ofb")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	t.Log("success CommonKeyCryptoWithAAD")
+}
+
+func Test_DeterministicCommonKeyCrypto(t *testing.T) {
+	testdata := "test@example.com"
+	commonKey := []byte("passw0rdpassw0rdpassw0rdpassw0rdpassw0rdpassw0rdpassw0rdpassw0rd")
+	cc, err := NewDeterministicCommonKeyCrypto(commonKey)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if cc.Mode() != CipherModeAesSiv {
+		t.Fatalf("failed Mode %s", cc.Mode())
+	}
+	encrypted := cc.Encrypt(testdata)
+	if encrypted != cc.Encrypt(testdata) {
+		t.Fatal("failed DeterministicCommonKeyCrypto is not deterministic")
+	}
+	if encrypted == cc.Encrypt("test2@example.com") {
+		t.Fatal("failed DeterministicCommonKeyCrypto ")
+	}
+	decrypted, err := cc.Decrypt(encrypted)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if decrypted != testdata {
+		t.Fatal("failed DeterministicCommonKeyCrypto ")
+	}
+
+	encryptedaad, err := cc.EncryptWithAAD(testdata, []byte("users.email"))
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if encryptedaad == encrypted {
+		t.Fatal("failed DeterministicCommonKeyCrypto with additional data")
+	}
+	if _, err := cc.DecryptWithAAD(encryptedaad, []byte("orders.email")); !errors.Is(err, ErrAuthenticationFailed) {
+		t.Fatalf("failed DecryptWithAAD with other additional data %#v", err)
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+
+	cc2, err := NewDeterministicCommonKeyCrypto(commonKey)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if cc2.Encrypt(testdata) != encrypted {
+		t.Fatal("failed DeterministicCommonKeyCrypto with same key")
+	}
+
+	if _, err := NewCommonKeyCryptoWithMode(commonKey, CipherModeAesSiv); err == nil {
+		t.Fatal("failed NewCommonKeyCryptoWithMode with aes-siv")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	ccgcm, err := NewCommonKeyCryptoWithMode(commonKey[:32], CipherModeAesGcm)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if _, err := ccgcm.Decrypt(encrypted); err == nil {
+		t.Fatal("failed Decrypt aes-siv with randomized mode")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	if _, err := cc.NewEncryptWriter(&bytes.Buffer{}); err == nil {
+		t.Fatal("failed NewEncryptWriter with aes-siv")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	if _, err := NewDeterministicCommonKeyCrypto([]byte("passw0rdpassw0rd")); err == nil {
+		t.Fatal("failed NewDeterministicCommonKeyCrypto ")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	t.Log("success DeterministicCommonKeyCrypto")
+}
+
+func Test_CommonKeyCryptoBytes(t *testing.T) {
+	testdata := []byte{0x00, 0xff, 0xfe, 0x80, 0x0a, 0x00}
+	commonKey := []byte("passw0rdpassw0rdpassw0rdpassw0rd")
+	cc, err := NewCommonKeyCryptoWithMode(commonKey, CipherModeAesGcm)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	ccofb, err := NewCommonKeyCrypto(commonKey)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	for _, c := range []*CommonKeyCrypto{cc, ccofb} {
+		encrypted, err := c.EncryptBytes(testdata)
+		if err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+		decrypted, err := c.DecryptBytes(encrypted)
+		if err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+		if bytes.Equal(decrypted, testdata) == false {
+			t.Fatalf("failed CommonKeyCryptoBytes %s", c.Mode())
+		}
+		encoded, err := c.EncryptString(string(testdata))
+		if err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+		if decrypted, err := c.Decrypt(encoded); err != nil || decrypted != string(testdata) {
+			t.Fatalf("failed EncryptString %s %#v", c.Mode(), err)
+		}
+	}
+
+	if cc.Encoding() != EncodingBase64Std {
+		t.Fatalf("failed Encoding %s", cc.Encoding())
+	}
+	for _, encoding := range []Encoding{EncodingBase64URL, EncodingBase64RawURL, EncodingHex, EncodingBase32, EncodingRaw} {
+		if err := cc.SetEncoding(encoding); err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+		encrypted := cc.Encrypt(string(testdata))
+		if encoding != EncodingRaw && strings.ContainsAny(encrypted, "+/") {
+			t.Fatalf("failed Encrypt with %s %s", encoding, encrypted)
+		}
+		decrypted, err := cc.Decrypt(encrypted)
+		if err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+		if decrypted != string(testdata) {
+			t.Fatalf("failed CommonKeyCryptoBytes with %s", encoding)
+		}
+	}
+	if err := cc.SetEncoding("base58"); err == nil {
+		t.Fatal("failed SetEncoding ")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	t.Log("success CommonKeyCryptoBytes")
+}
@@ -0,0 +1,119 @@
+package commonkeycrypto
+
+import (
+	"errors"
+	"sync"
+)
+
+const (
+	errorInvalidKeyID     = "Invalid key ID"
+	errorDuplicateKeyID   = "Key ID already exists"
+	errorKeyNotFound      = "Key not found"
+	errorNoPrimaryKey     = "No primary key"
+	errorRetirePrimaryKey = "Primary key cannot be retired"
+	maxKeyIDLength        = 255
+)
+
+// Keyring represents a set of common keys by key ID with one primary key.
+// It is safe for concurrent use.
+type Keyring struct {
+	mu      sync.RWMutex
+	keys    map[string]*CommonKeyCrypto
+	primary string
+}
+
+// NewKeyring create Keyring struct
+func NewKeyring() *Keyring {
+	return &Keyring{
+		keys: make(map[string]*CommonKeyCrypto),
+	}
+}
+
+// Add adds a common key with key ID. The first key added becomes the primary key.
+func (kr *Keyring) Add(keyID string, commonKey []byte, mode CipherMode) error {
+	if keyID == "" || len(keyID) > maxKeyIDLength {
+		return errors.New(errorInvalidKeyID)
+	}
+	if _, ok := cipherAlgorithms[mode]; !ok || mode == CipherModeAesSiv {
+		return errors.New(errorInvalidCipherMode)
+	}
+	ck, err := NewCommonKeyCryptoWithMode(commonKey, mode)
+	if err != nil {
+		return err
+	}
+	ck.keyID = keyID
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+	if _, ok := kr.keys[keyID]; ok {
+		return errors.New(errorDuplicateKeyID)
+	}
+	kr.keys[keyID] = ck
+	if kr.primary == "" {
+		kr.primary = keyID
+	}
+	return nil
+}
+
+// Retire removes a key so that data encrypted with it can no longer be decrypted.
+// The primary key cannot be retired.
+func (kr *Keyring) Retire(keyID string) error {
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+	if _, ok := kr.keys[keyID]; !ok {
+		return errors.New(errorKeyNotFound)
+	}
+	if kr.primary == keyID {
+		return errors.New(errorRetirePrimaryKey)
+	}
+	delete(kr.keys, keyID)
+	return nil
+}
+
+// Promote makes a key the primary key used to encrypt
+func (kr *Keyring) Promote(keyID string) error {
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+	if _, ok := kr.keys[keyID]; !ok {
+		return errors.New(errorKeyNotFound)
+	}
+	kr.primary = keyID
+	return nil
+}
+
+// Primary returns the primary key ID
+func (kr *Keyring) Primary() string {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+	return kr.primary
+}
+
+// KeyIDs returns the key IDs in the keyring
+func (kr *Keyring) KeyIDs() []string {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+	keyIDs := make([]string, 0, len(kr.keys))
+	for keyID := range kr.keys {
+		keyIDs = append(keyIDs, keyID)
+	}
+	return keyIDs
+}
+
+func (kr *Keyring) primaryKey() (*CommonKeyCrypto, error) {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+	ck, ok := kr.keys[kr.primary]
+	if !ok {
+		return nil, errors.New(errorNoPrimaryKey)
+	}
+	return ck, nil
+}
+
+func (kr *Keyring) key(keyID string) (*CommonKeyCrypto, error) {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+	ck, ok := kr.keys[keyID]
+	if !ok {
+		return nil, errors.New(errorKeyNotFound)
+	}
+	return ck, nil
+}
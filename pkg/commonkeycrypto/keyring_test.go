@@ -0,0 +1,122 @@
+package commonkeycrypto
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func Test_Keyring(t *testing.T) {
+	testdata := "testdata"
+	keyring := NewKeyring()
+	if _, err := NewCommonKeyCryptoWithKeyring(keyring); err == nil {
+		t.Fatal("failed NewCommonKeyCryptoWithKeyring with empty keyring")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	if _, err := NewCommonKeyCryptoWithKeyring(nil); err == nil {
+		t.Fatal("failed NewCommonKeyCryptoWithKeyring without keyring")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+
+	if err := keyring.Add("key-2023", []byte("passw0rdpassw0rdpassw0rdpassw0rd"), CipherModeAesGcm); err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	cc, err := NewCommonKeyCryptoWithKeyring(keyring)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	encrypted2023 := cc.Encrypt(testdata)
+	if err := keyring.Add("key-2024", []byte("passw1rdpassw1rdpassw1rdpassw1rd"), CipherModeXChaCha20Poly1305); err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if err := keyring.Add("key-2024", []byte("passw1rdpassw1rdpassw1rdpassw1rd"), CipherModeAesGcm); err == nil {
+		t.Fatal("failed Add with duplicate key ID")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	if keyring.Primary() != "key-2023" || cc.Mode() != CipherModeAesGcm {
+		t.Fatalf("failed Primary %s", keyring.Primary())
+	}
+	if cc.KeyID() != "key-2023" {
+		t.Fatalf("failed KeyID %s", cc.KeyID())
+	}
+	if err := cc.SetKeyID("key-2023"); err == nil {
+		t.Fatal("failed SetKeyID with keyring")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	if err := keyring.Promote("key-2024"); err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	encrypted2024 := cc.Encrypt(testdata)
+
+	ck2024, err := NewCommonKeyCryptoWithMode([]byte("passw1rdpassw1rdpassw1rdpassw1rd"), CipherModeXChaCha20Poly1305)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if err := ck2024.SetKeyID("key-2024"); err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if decrypted, err := ck2024.Decrypt(encrypted2024); err != nil || decrypted != testdata {
+		t.Fatalf("failed Decrypt with primary key %#v", err)
+	}
+	for _, encrypted := range []string{encrypted2023, encrypted2024} {
+		decrypted, err := cc.Decrypt(encrypted)
+		if err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+		if decrypted != testdata {
+			t.Fatal("failed Keyring ")
+		}
+	}
+
+	if err := keyring.Retire("key-2024"); err == nil {
+		t.Fatal("failed Retire primary key")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	if err := keyring.Retire("key-2023"); err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if _, err := cc.Decrypt(encrypted2023); err == nil {
+		t.Fatal("failed Decrypt with retired key")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	if err := keyring.Promote("key-2023"); err == nil {
+		t.Fatal("failed Promote retired key")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if decrypted, err := cc.Decrypt(cc.Encrypt(testdata)); err != nil || decrypted != testdata {
+					t.Errorf("failed concurrent Keyring %#v", err)
+					return
+				}
+			}
+		}()
+	}
+	for i := 0; i < 50; i++ {
+		keyID := fmt.Sprintf("key-concurrent-%d", i)
+		if err := keyring.Add(keyID, []byte("passw2rdpassw2rdpassw2rdpassw2rd"), CipherModeAesGcm); err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+		if i%2 == 0 {
+			if err := keyring.Promote(keyID); err != nil {
+				t.Fatalf("failed test %#v", err)
+			}
+		} else if err := keyring.Retire(keyID); err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+	}
+	wg.Wait()
+	t.Log("success Keyring")
+}
@@ -0,0 +1,66 @@
+package commonkeycrypto
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+
+	"github.com/howood/cryptotools/internal/encrypter"
+	"github.com/howood/cryptotools/internal/parser"
+)
+
+const errorStreamNotSupported = "Streaming is not supported in this cipher mode"
+
+// NewEncryptWriter returns a writer encrypting data written to it to w.
+// The envelope header is written first and the data follows in authenticated chunks,
+// so that truncation and reordering are detected by NewDecryptReader.
+// Each stream is encrypted with a key derived from the common key and a random salt stored in the header.
+// Close must be called to write the last chunk. It does not close w.
+func (ck *CommonKeyCrypto) NewEncryptWriter(w io.Writer) (io.WriteCloser, error) {
+	envelope, encrypterAead, err := ck.newEnvelope()
+	if err != nil {
+		return nil, err
+	}
+	streamEncrypter, ok := encrypterAead.(aeadStreamEncrypter)
+	if !ok {
+		return nil, errors.New(errorStreamNotSupported)
+	}
+	envelope.Nonce = make([]byte, encrypter.StreamSaltSize)
+	if _, err := rand.Read(envelope.Nonce); err != nil {
+		return nil, err
+	}
+	header, err := parser.MarshalEnvelope(envelope)
+	if err != nil {
+		return nil, err
+	}
+	additionalData, err := parser.EnvelopeAdditionalData(envelope, nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+	return streamEncrypter.NewStreamWriter(envelope.Nonce, additionalData, w)
+}
+
+// NewDecryptReader returns a reader decrypting data written by NewEncryptWriter from r.
+// Read returns ErrAuthenticationFailed when the stream has been modified, reordered or truncated.
+func (ck *CommonKeyCrypto) NewDecryptReader(r io.Reader) (io.Reader, error) {
+	envelope, err := parser.ReadEnvelopeHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	encrypterAead, err := ck.encrypterForEnvelope(envelope)
+	if err != nil {
+		return nil, err
+	}
+	streamEncrypter, ok := encrypterAead.(aeadStreamEncrypter)
+	if !ok {
+		return nil, errors.New(errorStreamNotSupported)
+	}
+	additionalData, err := parser.EnvelopeAdditionalData(envelope, nil)
+	if err != nil {
+		return nil, err
+	}
+	return streamEncrypter.NewStreamReader(envelope.Nonce, additionalData, r)
+}
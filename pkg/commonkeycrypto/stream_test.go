@@ -0,0 +1,104 @@
+package commonkeycrypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"testing"
+)
+
+func Test_CommonKeyCryptoStream(t *testing.T) {
+	commonKey := []byte("passw0rdpassw0rdpassw0rdpassw0rd")
+	testdata := make([]byte, 200*1024+7)
+	if _, err := rand.Read(testdata); err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	for _, mode := range []CipherMode{CipherModeAesGcm, CipherModeXChaCha20Poly1305} {
+		cc, err := NewCommonKeyCryptoWithMode(commonKey, mode)
+		if err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+		var encrypted bytes.Buffer
+		writer, err := cc.NewEncryptWriter(&encrypted)
+		if err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+		if _, err := io.Copy(writer, bytes.NewReader(testdata)); err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+		if err := writer.Close(); err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+
+		reader, err := cc.NewDecryptReader(bytes.NewReader(encrypted.Bytes()))
+		if err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+		decrypted, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+		if bytes.Equal(decrypted, testdata) == false {
+			t.Fatalf("failed CommonKeyCryptoStream %s", mode)
+		}
+
+		truncated := encrypted.Bytes()[:encrypted.Len()-100]
+		reader, err = cc.NewDecryptReader(bytes.NewReader(truncated))
+		if err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+		if _, err := io.ReadAll(reader); !errors.Is(err, ErrAuthenticationFailed) {
+			t.Fatalf("failed CommonKeyCryptoStream truncated %#v", err)
+		} else {
+			t.Logf("failed test %#v", err)
+		}
+	}
+
+	cc, err := NewCommonKeyCryptoWithMode(commonKey, CipherModeAesGcm)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	var encryptedStreams [2]bytes.Buffer
+	for i := range encryptedStreams {
+		writer, err := cc.NewEncryptWriter(&encryptedStreams[i])
+		if err != nil {
+			t.Fatalf("failed test %#v", err)
+		}
+		writer.Write(testdata)
+		writer.Close()
+	}
+	if bytes.Equal(encryptedStreams[0].Bytes(), encryptedStreams[1].Bytes()) {
+		t.Fatal("failed CommonKeyCryptoStream ciphertexts of the same plaintext are equal")
+	}
+
+	cc, err = NewCommonKeyCryptoWithPassphrase([]byte("passphrase"), CipherModeXChaCha20Poly1305, KeyDerivationScrypt)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	var encrypted bytes.Buffer
+	writer, err := cc.NewEncryptWriter(&encrypted)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	writer.Write([]byte("testdata"))
+	writer.Close()
+	reader, err := cc.NewDecryptReader(&encrypted)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if decrypted, err := io.ReadAll(reader); err != nil || string(decrypted) != "testdata" {
+		t.Fatalf("failed CommonKeyCryptoStream with passphrase %#v", err)
+	}
+
+	ccofb, err := NewCommonKeyCrypto(commonKey)
+	if err != nil {
+		t.Fatalf("failed test %#v", err)
+	}
+	if _, err := ccofb.NewEncryptWriter(&encrypted); err == nil {
+		t.Fatal("failed NewEncryptWriter with aes-ofb")
+	} else {
+		t.Logf("failed test %#v", err)
+	}
+	t.Log("success CommonKeyCryptoStream")
+}
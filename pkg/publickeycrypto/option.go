@@ -0,0 +1,211 @@
+package publickeycrypto
+
+import (
+	"crypto"
+
+	"github.com/howood/cryptotools/internal/entity"
+)
+
+// RsaPadding is RSA encryption padding
+type RsaPadding string
+
+const (
+	// RsaPaddingOAEP is RSA-OAEP padding. It is the default
+	RsaPaddingOAEP RsaPadding = "oaep"
+	// RsaPaddingPKCS1v15 is PKCS #1 v1.5 padding kept for compatibility
+	RsaPaddingPKCS1v15 RsaPadding = "pkcs1v15"
+)
+
+// EciesKDF is ECIES key derivation function
+type EciesKDF entity.EciesKDF
+
+const (
+	// EciesKDFX963SHA256 is ANSI X9.63 KDF with SHA-256
+	EciesKDFX963SHA256 EciesKDF = EciesKDF(entity.EciesKDFX963SHA256)
+	// EciesKDFHKDFSHA256 is HKDF with SHA-256
+	EciesKDFHKDFSHA256 EciesKDF = EciesKDF(entity.EciesKDFHKDFSHA256)
+)
+
+// EciesCipher is ECIES data encryption
+type EciesCipher entity.EciesCipher
+
+const (
+	// EciesCipherAes256Gcm is AES-256-GCM
+	EciesCipherAes256Gcm EciesCipher = EciesCipher(entity.EciesCipherAes256Gcm)
+	// EciesCipherAes256CtrHmacSHA256 is AES-256-CTR with HMAC-SHA256
+	EciesCipherAes256CtrHmacSHA256 EciesCipher = EciesCipher(entity.EciesCipherAes256CtrHmacSHA256)
+)
+
+// RsaSignatureScheme is RSA signature scheme
+type RsaSignatureScheme entity.RsaSignatureScheme
+
+const (
+	// RsaSignatureSchemePSS is RSASSA-PSS. It is the default
+	RsaSignatureSchemePSS RsaSignatureScheme = RsaSignatureScheme(entity.RsaSignatureSchemePSS)
+	// RsaSignatureSchemePKCS1v15 is RSASSA-PKCS1-v1_5
+	RsaSignatureSchemePKCS1v15 RsaSignatureScheme = RsaSignatureScheme(entity.RsaSignatureSchemePKCS1v15)
+)
+
+// EcdsaSignatureFormat is ECDSA signature encoding
+type EcdsaSignatureFormat entity.EcdsaSignatureFormat
+
+const (
+	// EcdsaSignatureFormatASN1 is ASN.1 DER encoded signature. It is the default
+	EcdsaSignatureFormatASN1 EcdsaSignatureFormat = EcdsaSignatureFormat(entity.EcdsaSignatureFormatASN1)
+	// EcdsaSignatureFormatRaw is fixed length r || s as in JWS and WebCrypto
+	EcdsaSignatureFormatRaw EcdsaSignatureFormat = EcdsaSignatureFormat(entity.EcdsaSignatureFormatRaw)
+)
+
+// KeyIDStrategy is how the key ID (kid) is derived from the key
+type KeyIDStrategy string
+
+const (
+	// KeyIDStrategyThumbprintSHA256 is RFC 7638 JWK thumbprint with SHA-256. It is the default
+	KeyIDStrategyThumbprintSHA256 KeyIDStrategy = "thumbprint-sha256"
+	// KeyIDStrategyThumbprintSHA512 is RFC 7638 JWK thumbprint with SHA-512
+	KeyIDStrategyThumbprintSHA512 KeyIDStrategy = "thumbprint-sha512"
+	// KeyIDStrategyLegacyMD5 is MD5 of the formatted key used by older versions
+	KeyIDStrategyLegacyMD5 KeyIDStrategy = "legacy-md5"
+)
+
+// Option is PublicKeyCrypto option
+type Option func(*options)
+
+type options struct {
+	rsaPadding     RsaPadding
+	oaepHash       crypto.Hash
+	oaepLabel      []byte
+	rsaHybrid      bool
+	ed25519Legacy  bool
+	eciesKDF       EciesKDF
+	eciesCipher    EciesCipher
+	signatureHash  crypto.Hash
+	rsaSignature   RsaSignatureScheme
+	ecdsaSignature EcdsaSignatureFormat
+	keyIDStrategy  KeyIDStrategy
+	keyID          string
+	jwksKeyID      string
+	strictPEM      bool
+	passphrase     []byte
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		rsaPadding:     RsaPaddingOAEP,
+		oaepHash:       crypto.SHA256,
+		signatureHash:  crypto.SHA256,
+		rsaSignature:   RsaSignatureSchemePSS,
+		ecdsaSignature: EcdsaSignatureFormatASN1,
+		keyIDStrategy:  KeyIDStrategyThumbprintSHA256,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithRsaOAEP sets RSA-OAEP padding with hash (SHA-1, SHA-256, SHA-384 or SHA-512) and an optional label.
+// The default is RSA-OAEP with SHA-256 and no label, which matches WebCrypto RSA-OAEP with SHA-256.
+func WithRsaOAEP(hash crypto.Hash, label []byte) Option {
+	return func(o *options) {
+		o.rsaPadding = RsaPaddingOAEP
+		o.oaepHash = hash
+		o.oaepLabel = append([]byte{}, label...)
+	}
+}
+
+// WithRsaPKCS1v15 sets legacy PKCS #1 v1.5 padding to decrypt data encrypted by older versions.
+// Without it, decrypting such data returns ErrRsaPaddingMismatch.
+func WithRsaPKCS1v15() Option {
+	return func(o *options) {
+		o.rsaPadding = RsaPaddingPKCS1v15
+	}
+}
+
+// WithRsaHybrid sets hybrid RSA encryption so that data of any length can be encrypted.
+// The data is encrypted with a random AES-256-GCM data key wrapped with RSA-OAEP.
+// Decrypt with RSA-OAEP unwraps hybrid ciphertext regardless of this option.
+func WithRsaHybrid() Option {
+	return func(o *options) {
+		o.rsaHybrid = true
+	}
+}
+
+// WithEd25519Legacy enables DecryptLegacy to decrypt the legacy ED25519 format of older versions.
+// The legacy format is unauthenticated, so use it only while migrating old data.
+// Decrypt never falls back to the legacy format.
+func WithEd25519Legacy() Option {
+	return func(o *options) {
+		o.ed25519Legacy = true
+	}
+}
+
+// WithEcies sets SEC 1 / ISO 18033-2 ECIES for ECDSA keys with kdf and eciesCipher.
+// The output is the uncompressed ephemeral public key || ciphertext || tag, and the keys are derived
+// from the ECDH shared secret with the ephemeral public key as shared info.
+// Without this option ECDSA keys use the legacy format of older versions.
+func WithEcies(kdf EciesKDF, eciesCipher EciesCipher) Option {
+	return func(o *options) {
+		o.eciesKDF = kdf
+		o.eciesCipher = eciesCipher
+	}
+}
+
+// WithSignatureHash sets the hash (SHA-256, SHA-384 or SHA-512) of RSA and ECDSA signatures. The default is SHA-256
+func WithSignatureHash(hash crypto.Hash) Option {
+	return func(o *options) {
+		o.signatureHash = hash
+	}
+}
+
+// WithRsaSignatureScheme sets RSA signature scheme. The default is RSASSA-PSS
+func WithRsaSignatureScheme(scheme RsaSignatureScheme) Option {
+	return func(o *options) {
+		o.rsaSignature = scheme
+	}
+}
+
+// WithEcdsaSignatureFormat sets ECDSA signature encoding. The default is ASN.1 DER
+func WithEcdsaSignatureFormat(format EcdsaSignatureFormat) Option {
+	return func(o *options) {
+		o.ecdsaSignature = format
+	}
+}
+
+// WithKeyIDStrategy sets how the key ID is derived from the key. The default is RFC 7638 JWK thumbprint with SHA-256
+func WithKeyIDStrategy(strategy KeyIDStrategy) Option {
+	return func(o *options) {
+		o.keyIDStrategy = strategy
+	}
+}
+
+// WithKeyID sets the key ID instead of deriving it from the key.
+// It does not select the key in JWKS read by LoadKey, which is set by WithJWKSKeyID
+func WithKeyID(kid string) Option {
+	return func(o *options) {
+		o.keyID = kid
+	}
+}
+
+// WithJWKSKeyID sets the kid of the key selected by LoadKey from JWKS with several keys
+func WithJWKSKeyID(kid string) Option {
+	return func(o *options) {
+		o.jwksKeyID = kid
+	}
+}
+
+// WithStrictPEM sets GetPrivateKey and GetPublicKey to write standard PEM labels readable by OpenSSL.
+// Publickey is PKIX PUBLIC KEY, and ED25519 and X25519 privatekey is PKCS8 PRIVATE KEY.
+// Without this option ECDSA and ED25519 keys are written with the labels of older versions.
+func WithStrictPEM() Option {
+	return func(o *options) {
+		o.strictPEM = true
+	}
+}
+
+// WithPassphrase sets the passphrase to decrypt encrypted private key read by LoadKey
+func WithPassphrase(passphrase []byte) Option {
+	return func(o *options) {
+		o.passphrase = append([]byte{}, passphrase...)
+	}
+}
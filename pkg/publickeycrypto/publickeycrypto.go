@@ -1,12 +1,15 @@
 package publickeycrypto
 
 import (
+	"crypto"
+	"crypto/ed25519"
 	"errors"
 
 	"github.com/howood/cryptotools/internal/encrypter"
 	"github.com/howood/cryptotools/internal/entity"
 	"github.com/howood/cryptotools/internal/generator"
 	"github.com/howood/cryptotools/internal/parser"
+	"github.com/howood/cryptotools/internal/signer"
 )
 
 // EncryptKeyType is EncryptKey KeyType
@@ -15,6 +18,13 @@ type EncryptKeyType entity.EncryptKeyType
 const (
 	errorInvalidEncryptType = "Invalid encryptType"
 	errorNoEncryptKeyType   = "No encrypt keytype"
+	errorInvalidRsaPadding  = "Invalid RSA padding"
+	errorAADNotSupported    = "Associated data is not supported with this encryptType"
+	errorSignNotSupported   = "Signature is not supported with this encryptType"
+	errorNoPrivateKey       = "no private key available"
+	errorInvalidKeyID       = "Invalid key ID strategy"
+	errorEmptyPassphrase    = "passphrase is empty"
+	errorInvalidKeyFormat   = "Invalid key format"
 )
 
 const (
@@ -24,6 +34,79 @@ const (
 	EncryptTypeECDSA EncryptKeyType = EncryptKeyType(entity.EncryptTypeECDSA)
 	// EncryptTypeED25519 is ED25519 KeyType
 	EncryptTypeED25519 EncryptKeyType = EncryptKeyType(entity.EncryptTypeED25519)
+	// EncryptTypeX25519 is X25519 KeyType, which only supports encryption
+	EncryptTypeX25519 EncryptKeyType = EncryptKeyType(entity.EncryptTypeX25519)
+)
+
+// Encoding is text encoding of ciphertext used by Encrypt and Decrypt
+type Encoding entity.Encoding
+
+const (
+	// EncodingBase64Std is standard base64 encoding. It is the default
+	EncodingBase64Std Encoding = Encoding(entity.EncodingBase64Std)
+	// EncodingBase64URL is URL-safe base64 encoding with padding
+	EncodingBase64URL Encoding = Encoding(entity.EncodingBase64URL)
+	// EncodingBase64RawURL is URL-safe base64 encoding without padding
+	EncodingBase64RawURL Encoding = Encoding(entity.EncodingBase64RawURL)
+	// EncodingHex is hex encoding
+	EncodingHex Encoding = Encoding(entity.EncodingHex)
+	// EncodingBase32 is base32 encoding
+	EncodingBase32 Encoding = Encoding(entity.EncodingBase32)
+	// EncodingRaw is no encoding, the ciphertext bytes are returned as string
+	EncodingRaw Encoding = Encoding(entity.EncodingRaw)
+)
+
+// PrivateKeyKDF is key derivation function of encrypted private key
+type PrivateKeyKDF entity.PrivateKeyKDF
+
+const (
+	// PrivateKeyKDFScrypt is scrypt. It is the default
+	PrivateKeyKDFScrypt PrivateKeyKDF = PrivateKeyKDF(entity.PrivateKeyKDFScrypt)
+	// PrivateKeyKDFPBKDF2SHA256 is PBKDF2 with HMAC-SHA256
+	PrivateKeyKDFPBKDF2SHA256 PrivateKeyKDF = PrivateKeyKDF(entity.PrivateKeyKDFPBKDF2SHA256)
+)
+
+// PrivateKeyCipher is encryption scheme of encrypted private key
+type PrivateKeyCipher entity.PrivateKeyCipher
+
+const (
+	// PrivateKeyCipherAes256Cbc is AES-256-CBC. It is the default, and readable by OpenSSL
+	PrivateKeyCipherAes256Cbc PrivateKeyCipher = PrivateKeyCipher(entity.PrivateKeyCipherAes256Cbc)
+	// PrivateKeyCipherAes256Gcm is AES-256-GCM defined in RFC 5084
+	PrivateKeyCipherAes256Gcm PrivateKeyCipher = PrivateKeyCipher(entity.PrivateKeyCipherAes256Gcm)
+)
+
+// PrivateKeyEncryptionOptions is key derivation function and cipher of encrypted private key
+type PrivateKeyEncryptionOptions struct {
+	KDF    PrivateKeyKDF
+	Cipher PrivateKeyCipher
+}
+
+// JWKOptions is kid, alg, use and key_ops of exported JWK
+type JWKOptions entity.JWKOptions
+
+// JWK use of signature and encryption keys
+const (
+	jwkUseSig = "sig"
+	jwkUseEnc = "enc"
+)
+
+// KeyFormat is serialization format of key
+type KeyFormat entity.KeyFormat
+
+const (
+	// KeyFormatPEM is PEM. Strict PEM labels are used with WithStrictPEM
+	KeyFormatPEM KeyFormat = KeyFormat(entity.KeyFormatPEM)
+	// KeyFormatDER is DER of PKCS8 privatekey and PKIX publickey
+	KeyFormatDER KeyFormat = KeyFormat(entity.KeyFormatDER)
+	// KeyFormatJWK is JSON Web Key
+	KeyFormatJWK KeyFormat = KeyFormat(entity.KeyFormatJWK)
+	// KeyFormatJWKS is JSON Web Key Set with one key
+	KeyFormatJWKS KeyFormat = KeyFormat(entity.KeyFormatJWKS)
+	// KeyFormatOpenSSH is OpenSSH privatekey, and authorized_keys line for publickey
+	KeyFormatOpenSSH KeyFormat = KeyFormat(entity.KeyFormatOpenSSH)
+	// KeyFormatAuthorizedKey is OpenSSH authorized_keys line. It is only for publickey
+	KeyFormatAuthorizedKey KeyFormat = KeyFormat(entity.KeyFormatAuthorizedKey)
 )
 
 // PublicKeyCrypto represents PublicKeyCrypto struct
@@ -32,166 +115,724 @@ type PublicKeyCrypto struct {
 	encrypterRsa     *encrypter.CryptoRsa
 	encrypterEcdsa   *encrypter.CryptoEcdsa
 	encrypterEd25519 *encrypter.CryptoEd25519
+	encrypterX25519  *encrypter.CryptoX25519
+	signerRsa        *signer.SignerRsa
+	signerEcdsa      *signer.SignerEcdsa
+	signerEd25519    *signer.SignerEd25519
+	encoding         Encoding
+	keyID            string
+	strictPEM        bool
+	jwkSignatureAlg  string
+	jwkEncryptionAlg string
 }
 
+// ErrInvalidSignature is returned when a signature does not match the data
+var ErrInvalidSignature = signer.ErrInvalidSignature
+
+// ErrRsaPaddingMismatch is returned when RSA-OAEP decryption fails.
+// Data encrypted by older versions uses PKCS #1 v1.5 padding and must be decrypted with WithRsaPKCS1v15.
+var ErrRsaPaddingMismatch = encrypter.ErrRsaOAEPDecryption
+
 // NewPublicKeyCrypto create PublicKeyCrypto struct
-func NewPublicKeyCrypto(bits int, encryptType EncryptKeyType) (*PublicKeyCrypto, error) {
+func NewPublicKeyCrypto(bits int, encryptType EncryptKeyType, opts ...Option) (*PublicKeyCrypto, error) {
 	encryptkey, err := generateEncryptKey(bits, encryptType)
 	if err != nil {
 		return nil, err
 	}
-	var encrypterRsa *encrypter.CryptoRsa
-	var encrypterEcdsa *encrypter.CryptoEcdsa
-	var encrypterEd25519 *encrypter.CryptoEd25519
-	switch encryptkey.Keytype {
-	case entity.EncryptTypeRSA:
-		encrypterRsa = encrypter.NewCryptoRsa(&encryptkey.RsaKey)
-	case entity.EncryptTypeECDSA:
-		encrypterEcdsa = encrypter.NewCryptoEcdsa(&encryptkey.EcdsaKey)
-	case entity.EncryptTypeED25519:
-		encrypterEd25519 = encrypter.NewCryptoEd25519(&encryptkey.Ed25519Key)
-	default:
-		return nil, errors.New(errorNoEncryptKeyType)
-	}
-	return &PublicKeyCrypto{
-		EncryptKey:       &encryptkey,
-		encrypterRsa:     encrypterRsa,
-		encrypterEcdsa:   encrypterEcdsa,
-		encrypterEd25519: encrypterEd25519,
-	}, nil
+	return newPublicKeyCrypto(encryptkey, opts)
 }
 
-// NewPublicKeyCryptoWithPEMPublicKey create PublicKeyCrypto struct with PEM Public Key
-func NewPublicKeyCryptoWithPEMPublicKey(publickey []byte, encryptType EncryptKeyType) (*PublicKeyCrypto, error) {
+// NewPublicKeyCryptoWithPEMPublicKey create PublicKeyCrypto struct with PEM Public Key.
+// The key type is taken from the key and an error is returned when it is not encryptType.
+func NewPublicKeyCryptoWithPEMPublicKey(publickey []byte, encryptType EncryptKeyType, opts ...Option) (*PublicKeyCrypto, error) {
 	encryptkey, err := generateKeyWithPEMPublicKey(publickey)
 	if err != nil {
 		return nil, err
 	}
-	var encrypterRsa *encrypter.CryptoRsa
-	var encrypterEcdsa *encrypter.CryptoEcdsa
-	var encrypterEd25519 *encrypter.CryptoEd25519
-	switch encryptkey.Keytype {
-	case entity.EncryptTypeRSA:
-		encrypterRsa = encrypter.NewCryptoRsa(&encryptkey.RsaKey)
-	case entity.EncryptTypeECDSA:
-		encrypterEcdsa = encrypter.NewCryptoEcdsa(&encryptkey.EcdsaKey)
-	case entity.EncryptTypeED25519:
-		encrypterEd25519 = encrypter.NewCryptoEd25519(&encryptkey.Ed25519Key)
-	default:
-		return nil, errors.New(errorNoEncryptKeyType)
+	if EncryptKeyType(encryptkey.Keytype) != encryptType {
+		return nil, errors.New(errorInvalidEncryptType)
 	}
-	return &PublicKeyCrypto{
-		EncryptKey:       &encryptkey,
-		encrypterRsa:     encrypterRsa,
-		encrypterEcdsa:   encrypterEcdsa,
-		encrypterEd25519: encrypterEd25519,
-	}, nil
+	return newPublicKeyCrypto(encryptkey, opts)
+}
+
+// NewPublicKeyCryptoWithPEMPrivateKey create PublicKeyCrypto struct with PEM Private Key.
+// PKCS #1, SEC 1 EC, PKCS #8 and OpenSSH private keys are supported, and the key type is taken from the key.
+// The public key is derived from the private key so that the instance can also encrypt and export the public key.
+func NewPublicKeyCryptoWithPEMPrivateKey(privatekey []byte, opts ...Option) (*PublicKeyCrypto, error) {
+	encryptkey, err := generateKeyWithPEMPrivateKey(privatekey)
+	if err != nil {
+		return nil, err
+	}
+	return newPublicKeyCrypto(encryptkey, opts)
+}
+
+// NewPublicKeyCryptoWithAuthorizedKey create PublicKeyCrypto struct with OpenSSH authorized_keys line.
+// ssh-rsa, ecdsa-sha2-* and ssh-ed25519 keys are supported, and the key type is taken from the key.
+func NewPublicKeyCryptoWithAuthorizedKey(authorizedkey []byte, opts ...Option) (*PublicKeyCrypto, error) {
+	encryptkey := entity.EncryptKey{}
+	if err := parser.DecodeAuthorizedKey(authorizedkey, &encryptkey); err != nil {
+		return nil, err
+	}
+	return newPublicKeyCrypto(encryptkey, opts)
+}
+
+// LoadAuthorizedKeys create PublicKeyCrypto structs for every key in OpenSSH authorized_keys file.
+// Blank lines and lines starting with # are skipped.
+func LoadAuthorizedKeys(authorizedkeys []byte, opts ...Option) ([]*PublicKeyCrypto, error) {
+	encryptkeys, err := parser.DecodeAuthorizedKeys(authorizedkeys)
+	if err != nil {
+		return nil, err
+	}
+	publickeycryptos := make([]*PublicKeyCrypto, 0, len(encryptkeys))
+	for _, encryptkey := range encryptkeys {
+		publickeycrypto, err := newPublicKeyCrypto(encryptkey, opts)
+		if err != nil {
+			return nil, err
+		}
+		publickeycryptos = append(publickeycryptos, publickeycrypto)
+	}
+	return publickeycryptos, nil
+}
+
+// NewPublicKeyCryptoWithJWK create PublicKeyCrypto struct with JWK.
+// RSA, EC and OKP (Ed25519, X25519) keys are supported, and the key type is taken from kty and crv.
+// When the JWK has the private key (d), the instance can also decrypt and sign.
+func NewPublicKeyCryptoWithJWK(jwk []byte, opts ...Option) (*PublicKeyCrypto, error) {
+	encryptkey, err := generateKeyWithJWK(jwk)
+	if err != nil {
+		return nil, err
+	}
+	return newPublicKeyCrypto(encryptkey, opts)
+}
+
+// NewPublicKeyCryptoWithEncryptedPEMPrivateKey create PublicKeyCrypto struct with PEM Private Key encrypted with passphrase.
+// PKCS #8 ENCRYPTED PRIVATE KEY, legacy encrypted PEM with DEK-Info header and OpenSSH private key with passphrase are supported.
+func NewPublicKeyCryptoWithEncryptedPEMPrivateKey(privatekey, passphrase []byte, opts ...Option) (*PublicKeyCrypto, error) {
+	encryptkey := entity.EncryptKey{}
+	if err := parser.DecodePrivateKeyWithPassphrase(privatekey, passphrase, &encryptkey); err != nil {
+		return nil, err
+	}
+	if err := setPublicKeyFromPrivateKey(&encryptkey); err != nil {
+		return nil, err
+	}
+	return newPublicKeyCrypto(encryptkey, opts)
+}
+
+// LoadKey create PublicKeyCrypto struct with key of any supported format, which is detected from the data.
+// PEM, DER, JWK, JWKS, OpenSSH private key and authorized_keys line are supported, with either public or private key.
+// Encrypted private key is decrypted with the passphrase set by WithPassphrase,
+// and the key in JWKS with several keys is selected by the kid set by WithJWKSKeyID.
+func LoadKey(key []byte, opts ...Option) (*PublicKeyCrypto, error) {
+	o := newOptions(opts)
+	encryptkey := entity.EncryptKey{}
+	if _, err := parser.DecodeKey(key, o.passphrase, o.jwksKeyID, &encryptkey); err != nil {
+		return nil, err
+	}
+	if containsPrivateKey(&encryptkey) {
+		if err := setPublicKeyFromPrivateKey(&encryptkey); err != nil {
+			return nil, err
+		}
+	}
+	return newPublicKeyCrypto(encryptkey, opts)
+}
+
+// ConvertPublicKey converts the publickey of key of any format supported by LoadKey to format
+func ConvertPublicKey(key []byte, format KeyFormat, opts ...Option) ([]byte, error) {
+	pc, err := LoadKey(key, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return pc.GetPublicKeyWithFormat(format)
+}
+
+// ConvertPrivateKey converts the privatekey of key of any format supported by LoadKey to format.
+// The passphrase set by WithPassphrase only decrypts key, and the converted privatekey is not encrypted.
+func ConvertPrivateKey(key []byte, format KeyFormat, opts ...Option) ([]byte, error) {
+	pc, err := LoadKey(key, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return pc.GetPrivateKeyWithFormat(format)
 }
 
 // NewPublicKeyCryptoWithJWKPublicKey create PublicKeyCrypto struct with JWK Public Key
-func NewPublicKeyCryptoWithJWKPublicKey(publickey []byte, encryptType EncryptKeyType) (*PublicKeyCrypto, error) {
+func NewPublicKeyCryptoWithJWKPublicKey(publickey []byte, encryptType EncryptKeyType, opts ...Option) (*PublicKeyCrypto, error) {
 	encryptkey, err := generateKeyWithJWKMPublicKey(publickey, encryptType)
 	if err != nil {
 		return nil, err
 	}
-	var encrypterRsa *encrypter.CryptoRsa
-	var encrypterEcdsa *encrypter.CryptoEcdsa
-	switch encryptkey.Keytype {
-	case entity.EncryptTypeRSA:
-		encrypterRsa = encrypter.NewCryptoRsa(&encryptkey.RsaKey)
-	case entity.EncryptTypeECDSA:
-		encrypterEcdsa = encrypter.NewCryptoEcdsa(&encryptkey.EcdsaKey)
-	default:
-		return nil, errors.New(errorNoEncryptKeyType)
-	}
-	return &PublicKeyCrypto{
-		EncryptKey:     &encryptkey,
-		encrypterRsa:   encrypterRsa,
-		encrypterEcdsa: encrypterEcdsa,
-	}, nil
+	return newPublicKeyCrypto(encryptkey, opts)
 }
 
-// Encrypt encrypts input data with publickey encryption
+// Encrypt encrypts input data with publickey encryption and encodes it with Encoding
 func (ck *PublicKeyCrypto) Encrypt(input string) (string, error) {
+	ciphertext, err := ck.EncryptBytes([]byte(input))
+	if err != nil {
+		return "", err
+	}
+	return parser.EncodeWithEncoding(ciphertext, entity.Encoding(ck.encoding))
+}
+
+// EncryptBytes encrypts binary input data with publickey encryption without encoding
+func (ck *PublicKeyCrypto) EncryptBytes(input []byte) ([]byte, error) {
 	switch ck.EncryptKey.Keytype {
 	case entity.EncryptTypeRSA:
-		return ck.encrypterRsa.EncryptWithBase64([]byte(input))
+		return ck.encrypterRsa.Encrypt(input)
 	case entity.EncryptTypeECDSA:
-		return ck.encrypterEcdsa.EncryptWithBase64([]byte(input))
+		return ck.encrypterEcdsa.Encrypt(input)
 	case entity.EncryptTypeED25519:
-		return ck.encrypterEd25519.EncryptWithBase64([]byte(input))
+		return ck.encrypterEd25519.Encrypt(input)
+	case entity.EncryptTypeX25519:
+		return ck.encrypterX25519.Encrypt(input)
 	default:
-		return "", errors.New(errorInvalidEncryptType)
+		return nil, errors.New(errorInvalidEncryptType)
 	}
 }
 
-// Decrypt decrypts input data with publickey encryption
+// Decrypt decrypts input data encoded with Encoding with publickey encryption
 func (ck *PublicKeyCrypto) Decrypt(input string) (string, error) {
+	inputdecoded, err := parser.DecodeWithEncoding(input, entity.Encoding(ck.encoding))
+	if err != nil {
+		return "", err
+	}
+	data, err := ck.DecryptBytes(inputdecoded)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// DecryptBytes decrypts binary input data encrypted by EncryptBytes
+func (ck *PublicKeyCrypto) DecryptBytes(input []byte) ([]byte, error) {
 	switch ck.EncryptKey.Keytype {
 	case entity.EncryptTypeRSA:
 		if ck.EncryptKey.RsaKey.PrivateKey == nil {
-			return "", errors.New("no private key available")
+			return nil, errors.New(errorNoPrivateKey)
 		}
-		data, err := ck.encrypterRsa.DecryptWithBase64(input)
-		if err != nil {
-			return "", err
+		return ck.encrypterRsa.Decrypt(input)
+	case entity.EncryptTypeECDSA:
+		if ck.EncryptKey.EcdsaKey.PrivateKey == nil {
+			return nil, errors.New(errorNoPrivateKey)
 		}
-		return string(data), nil
+		return ck.encrypterEcdsa.Decrypt(input)
+	case entity.EncryptTypeED25519:
+		if ck.EncryptKey.Ed25519Key.PrivateKey == nil {
+			return nil, errors.New(errorNoPrivateKey)
+		}
+		return ck.encrypterEd25519.Decrypt(input)
+	case entity.EncryptTypeX25519:
+		if ck.EncryptKey.X25519Key.PrivateKey == nil {
+			return nil, errors.New(errorNoPrivateKey)
+		}
+		return ck.encrypterX25519.Decrypt(input)
+	default:
+		return nil, errors.New(errorInvalidEncryptType)
+	}
+}
+
+// DecryptLegacy decrypts input data in the legacy ED25519 format of older versions.
+// It requires WithEd25519Legacy. The legacy format is unauthenticated, so tampered data is not detected.
+func (ck *PublicKeyCrypto) DecryptLegacy(input string) (string, error) {
+	if ck.EncryptKey.Keytype != entity.EncryptTypeED25519 {
+		return "", errors.New(errorInvalidEncryptType)
+	}
+	if ck.EncryptKey.Ed25519Key.PrivateKey == nil {
+		return "", errors.New(errorNoPrivateKey)
+	}
+	inputdecoded, err := parser.DecodeWithEncoding(input, entity.Encoding(ck.encoding))
+	if err != nil {
+		return "", err
+	}
+	data, err := ck.encrypterEd25519.DecryptLegacy(inputdecoded)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// EncryptWithAAD encrypts input data with publickey encryption and binds it to additionalData.
+// The same additionalData must be given to DecryptWithAAD. It is supported with ECDSA keys with WithEcies, ED25519 and X25519 keys.
+func (ck *PublicKeyCrypto) EncryptWithAAD(input string, additionalData []byte) (string, error) {
+	var ciphertext []byte
+	var err error
+	switch ck.EncryptKey.Keytype {
+	case entity.EncryptTypeECDSA:
+		ciphertext, err = ck.encrypterEcdsa.EncryptWithAAD([]byte(input), additionalData)
+	case entity.EncryptTypeED25519:
+		ciphertext, err = ck.encrypterEd25519.EncryptWithAAD([]byte(input), additionalData)
+	case entity.EncryptTypeX25519:
+		ciphertext, err = ck.encrypterX25519.EncryptWithAAD([]byte(input), additionalData)
+	case entity.EncryptTypeRSA:
+		return "", errors.New(errorAADNotSupported)
+	default:
+		return "", errors.New(errorInvalidEncryptType)
+	}
+	if err != nil {
+		return "", err
+	}
+	return parser.EncodeWithEncoding(ciphertext, entity.Encoding(ck.encoding))
+}
+
+// DecryptWithAAD decrypts input data encrypted by EncryptWithAAD.
+// It fails when additionalData differs from the one used to encrypt.
+func (ck *PublicKeyCrypto) DecryptWithAAD(input string, additionalData []byte) (string, error) {
+	var data []byte
+	switch ck.EncryptKey.Keytype {
 	case entity.EncryptTypeECDSA:
 		if ck.EncryptKey.EcdsaKey.PrivateKey == nil {
-			return "", errors.New("no private key available")
+			return "", errors.New(errorNoPrivateKey)
 		}
-		data, err := ck.encrypterEcdsa.DecryptWithBase64(input)
+		inputdecoded, err := parser.DecodeWithEncoding(input, entity.Encoding(ck.encoding))
 		if err != nil {
 			return "", err
 		}
-		return string(data), nil
+		if data, err = ck.encrypterEcdsa.DecryptWithAAD(inputdecoded, additionalData); err != nil {
+			return "", err
+		}
 	case entity.EncryptTypeED25519:
 		if ck.EncryptKey.Ed25519Key.PrivateKey == nil {
-			return "", errors.New("no private key available")
+			return "", errors.New(errorNoPrivateKey)
+		}
+		inputdecoded, err := parser.DecodeWithEncoding(input, entity.Encoding(ck.encoding))
+		if err != nil {
+			return "", err
+		}
+		if data, err = ck.encrypterEd25519.DecryptWithAAD(inputdecoded, additionalData); err != nil {
+			return "", err
+		}
+	case entity.EncryptTypeX25519:
+		if ck.EncryptKey.X25519Key.PrivateKey == nil {
+			return "", errors.New(errorNoPrivateKey)
 		}
-		data, err := ck.encrypterEd25519.DecryptWithBase64(input)
+		inputdecoded, err := parser.DecodeWithEncoding(input, entity.Encoding(ck.encoding))
 		if err != nil {
 			return "", err
 		}
-		return string(data), nil
+		if data, err = ck.encrypterX25519.DecryptWithAAD(inputdecoded, additionalData); err != nil {
+			return "", err
+		}
+	case entity.EncryptTypeRSA:
+		return "", errors.New(errorAADNotSupported)
 	default:
 		return "", errors.New(errorInvalidEncryptType)
 	}
+	return string(data), nil
+}
+
+// Sign signs data with the private key and encodes the signature with Encoding.
+// RSA uses RSASSA-PSS or PKCS #1 v1.5, ECDSA uses ASN.1 or raw r || s and ED25519 uses pure Ed25519.
+func (ck *PublicKeyCrypto) Sign(data string) (string, error) {
+	signature, err := ck.SignBytes([]byte(data))
+	if err != nil {
+		return "", err
+	}
+	return parser.EncodeWithEncoding(signature, entity.Encoding(ck.encoding))
+}
+
+// SignBytes signs binary data with the private key without encoding
+func (ck *PublicKeyCrypto) SignBytes(data []byte) ([]byte, error) {
+	switch ck.EncryptKey.Keytype {
+	case entity.EncryptTypeRSA:
+		if ck.EncryptKey.RsaKey.PrivateKey == nil {
+			return nil, errors.New(errorNoPrivateKey)
+		}
+		return ck.signerRsa.Sign(data)
+	case entity.EncryptTypeECDSA:
+		if ck.EncryptKey.EcdsaKey.PrivateKey == nil {
+			return nil, errors.New(errorNoPrivateKey)
+		}
+		return ck.signerEcdsa.Sign(data)
+	case entity.EncryptTypeED25519:
+		if ck.EncryptKey.Ed25519Key.PrivateKey == nil {
+			return nil, errors.New(errorNoPrivateKey)
+		}
+		return ck.signerEd25519.Sign(data)
+	case entity.EncryptTypeX25519:
+		return nil, errors.New(errorSignNotSupported)
+	default:
+		return nil, errors.New(errorInvalidEncryptType)
+	}
+}
+
+// Verify verifies signature encoded with Encoding of data with the public key.
+// It returns ErrInvalidSignature when the signature does not match.
+func (ck *PublicKeyCrypto) Verify(data, signature string) error {
+	signaturedecoded, err := parser.DecodeWithEncoding(signature, entity.Encoding(ck.encoding))
+	if err != nil {
+		return err
+	}
+	return ck.VerifyBytes([]byte(data), signaturedecoded)
+}
+
+// VerifyBytes verifies binary signature of binary data with the public key
+func (ck *PublicKeyCrypto) VerifyBytes(data, signature []byte) error {
+	switch ck.EncryptKey.Keytype {
+	case entity.EncryptTypeRSA:
+		return ck.signerRsa.Verify(data, signature)
+	case entity.EncryptTypeECDSA:
+		return ck.signerEcdsa.Verify(data, signature)
+	case entity.EncryptTypeED25519:
+		return ck.signerEd25519.Verify(data, signature)
+	case entity.EncryptTypeX25519:
+		return errors.New(errorSignNotSupported)
+	default:
+		return errors.New(errorInvalidEncryptType)
+	}
+}
+
+// SetEncoding sets the encoding of ciphertext and signature used by Encrypt, Decrypt, Sign and Verify. The default is EncodingBase64Std
+func (ck *PublicKeyCrypto) SetEncoding(encoding Encoding) error {
+	if err := parser.ValidateEncoding(entity.Encoding(encoding)); err != nil {
+		return err
+	}
+	ck.encoding = encoding
+	return nil
+}
+
+// Encoding returns the encoding of ciphertext
+func (ck *PublicKeyCrypto) Encoding() Encoding {
+	if ck.encoding == "" {
+		return EncodingBase64Std
+	}
+	return ck.encoding
+}
+
+// KeyID returns the key ID used as kid of JWK.
+// It is RFC 7638 JWK thumbprint with SHA-256 of the publickey unless set by WithKeyIDStrategy or WithKeyID.
+func (ck *PublicKeyCrypto) KeyID() string {
+	return ck.keyID
+}
+
+// Comment returns the comment of the OpenSSH key
+func (ck *PublicKeyCrypto) Comment() string {
+	return ck.EncryptKey.Comment
+}
+
+// SetComment sets the comment of the OpenSSH key written by GetPrivateKeyOpenSSH
+func (ck *PublicKeyCrypto) SetComment(comment string) {
+	ck.EncryptKey.Comment = comment
+}
+
+// AuthorizedKeyOptions returns the options of the OpenSSH authorized_keys line
+func (ck *PublicKeyCrypto) AuthorizedKeyOptions() []string {
+	return ck.EncryptKey.Options
 }
 
 // GetPrivateKey gets privatekey
 func (ck *PublicKeyCrypto) GetPrivateKey() ([]byte, error) {
+	if ck.strictPEM {
+		if !ck.hasPrivateKey() {
+			return nil, errors.New(errorNoPrivateKey)
+		}
+		return parser.EncodePrivateKeyStrict(ck.EncryptKey)
+	}
 	return parser.EncodePrivateKey(ck.EncryptKey)
 }
 
-// GetPrivateKeyPKCS8 gets pkcs8 privatekey
-func (ck *PublicKeyCrypto) GetPrivateKeyPKCS8() ([]byte, error) {
+// GetPrivateKeyEncrypted gets PKCS #8 ENCRYPTED PRIVATE KEY encrypted with passphrase using scrypt and AES-256-CBC
+func (ck *PublicKeyCrypto) GetPrivateKeyEncrypted(passphrase []byte) ([]byte, error) {
+	return ck.GetPrivateKeyEncryptedWithOptions(passphrase, PrivateKeyEncryptionOptions{})
+}
+
+// GetPrivateKeyEncryptedWithOptions gets PKCS #8 ENCRYPTED PRIVATE KEY encrypted with passphrase using PBES2 with options.
+// Empty KDF and Cipher are scrypt and AES-256-CBC.
+func (ck *PublicKeyCrypto) GetPrivateKeyEncryptedWithOptions(passphrase []byte, options PrivateKeyEncryptionOptions) ([]byte, error) {
+	if !ck.hasPrivateKey() {
+		return nil, errors.New(errorNoPrivateKey)
+	}
+	if len(passphrase) == 0 {
+		return nil, errors.New(errorEmptyPassphrase)
+	}
+	return parser.EncodeEncryptedPrivateKey(ck.EncryptKey, passphrase, entity.PrivateKeyEncryption{
+		KDF:    entity.PrivateKeyKDF(options.KDF),
+		Cipher: entity.PrivateKeyCipher(options.Cipher),
+	})
+}
+
+// GetPrivateKeyOpenSSH gets OpenSSH privatekey with the comment.
+// When passphrase is not empty the privatekey is encrypted with bcrypt-pbkdf and aes256-ctr as ssh-keygen does.
+func (ck *PublicKeyCrypto) GetPrivateKeyOpenSSH(passphrase []byte) ([]byte, error) {
+	if !ck.hasPrivateKey() {
+		return nil, errors.New(errorNoPrivateKey)
+	}
 	switch ck.EncryptKey.Keytype {
-	case entity.EncryptTypeRSA:
-		return parser.EncodeRsaPrivateKeyPKCS8(ck.EncryptKey.RsaKey.PrivateKey)
+	case entity.EncryptTypeRSA, entity.EncryptTypeECDSA, entity.EncryptTypeED25519:
+		return parser.EncodeOpenSSHPrivateKey(ck.EncryptKey, passphrase)
 	default:
 		return nil, errors.New(errorInvalidEncryptType)
 	}
 }
 
+// GetPrivateKeyPKCS8 gets pkcs8 privatekey
+func (ck *PublicKeyCrypto) GetPrivateKeyPKCS8() ([]byte, error) {
+	if !ck.hasPrivateKey() {
+		return nil, errors.New(errorNoPrivateKey)
+	}
+	return parser.EncodePKCS8PrivateKey(ck.EncryptKey)
+}
+
 // GetPublicKey gets publickey
 func (ck *PublicKeyCrypto) GetPublicKey() ([]byte, error) {
+	if ck.strictPEM {
+		return parser.EncodePublicKeyStrict(ck.EncryptKey)
+	}
 	return parser.EncodePublicKey(ck.EncryptKey)
 }
 
+// GetPublicKeyWithFormat gets publickey with format
+func (ck *PublicKeyCrypto) GetPublicKeyWithFormat(format KeyFormat) ([]byte, error) {
+	switch format {
+	case KeyFormatPEM:
+		return ck.GetPublicKey()
+	case KeyFormatDER:
+		return parser.MarshalPKIXPublicKey(ck.EncryptKey)
+	case KeyFormatJWK:
+		return ck.GetPublicKeyWithJWK()
+	case KeyFormatJWKS:
+		jwk, err := ck.GetPublicKeyWithJWK()
+		if err != nil {
+			return nil, err
+		}
+		return parser.EncodeJWKS(jwk)
+	case KeyFormatOpenSSH, KeyFormatAuthorizedKey:
+		return parser.EncodeAuthorizedKey(ck.EncryptKey)
+	default:
+		return nil, errors.New(errorInvalidKeyFormat)
+	}
+}
+
+// GetPrivateKeyWithFormat gets privatekey with format. KeyFormatAuthorizedKey is not supported
+func (ck *PublicKeyCrypto) GetPrivateKeyWithFormat(format KeyFormat) ([]byte, error) {
+	if !ck.hasPrivateKey() {
+		return nil, errors.New(errorNoPrivateKey)
+	}
+	switch format {
+	case KeyFormatPEM:
+		return ck.GetPrivateKey()
+	case KeyFormatDER:
+		return parser.MarshalPKCS8PrivateKey(ck.EncryptKey)
+	case KeyFormatJWK:
+		return ck.GetPrivateKeyWithJWK()
+	case KeyFormatJWKS:
+		jwk, err := ck.GetPrivateKeyWithJWK()
+		if err != nil {
+			return nil, err
+		}
+		return parser.EncodeJWKS(jwk)
+	case KeyFormatOpenSSH:
+		return ck.GetPrivateKeyOpenSSH(nil)
+	default:
+		return nil, errors.New(errorInvalidKeyFormat)
+	}
+}
+
+// GetPublicKeyPKCS1 gets PKCS1 RSA PUBLIC KEY publickey
+func (ck *PublicKeyCrypto) GetPublicKeyPKCS1() ([]byte, error) {
+	if ck.EncryptKey.Keytype != entity.EncryptTypeRSA {
+		return nil, errors.New(errorInvalidEncryptType)
+	}
+	return parser.EncodeRsaPublicKeyPKCS1(ck.EncryptKey.RsaKey.PublicKey), nil
+}
+
 // GetPublicKeyWithJWK gets jwk publickey
 func (ck *PublicKeyCrypto) GetPublicKeyWithJWK() ([]byte, error) {
-	var kid string
-	switch ck.EncryptKey.Keytype {
+	return ck.GetPublicKeyWithJWKOptions(JWKOptions{})
+}
+
+// GetPublicKeyWithJWKOptions gets jwk publickey with kid, alg, use and key_ops.
+// When KeyID is empty KeyID() is used. When Algorithm is empty, alg is derived from the signature
+// or encryption scheme of this PublicKeyCrypto selected by Use, and is left out when no JWA alg matches it.
+func (ck *PublicKeyCrypto) GetPublicKeyWithJWKOptions(options JWKOptions) ([]byte, error) {
+	jwkoptions := ck.jwkOptions(options)
+	if jwkoptions.Algorithm == "" {
+		jwkoptions.Algorithm = ck.jwkAlgorithm(jwkoptions.Use)
+	}
+	return parser.GenerateJSONWebKeyWithEncryptPublicKeyOptions(ck.EncryptKey, jwkoptions)
+}
+
+// GetPrivateKeyWithJWK gets jwk privatekey
+func (ck *PublicKeyCrypto) GetPrivateKeyWithJWK() ([]byte, error) {
+	return ck.GetPrivateKeyWithJWKOptions(JWKOptions{})
+}
+
+// GetPrivateKeyWithJWKOptions gets jwk privatekey with kid, alg, use and key_ops.
+// When KeyID is empty KeyID() is used, and alg is set only when Algorithm is given.
+func (ck *PublicKeyCrypto) GetPrivateKeyWithJWKOptions(options JWKOptions) ([]byte, error) {
+	if !ck.hasPrivateKey() {
+		return nil, errors.New(errorNoPrivateKey)
+	}
+	return parser.GenerateJSONWebKeyWithEncryptPrivateKeyOptions(ck.EncryptKey, ck.jwkOptions(options))
+}
+
+func (ck *PublicKeyCrypto) jwkOptions(options JWKOptions) entity.JWKOptions {
+	jwkoptions := entity.JWKOptions(options)
+	if jwkoptions.KeyID == "" {
+		jwkoptions.KeyID = ck.keyID
+	}
+	return jwkoptions
+}
+
+// jwkAlgorithm returns the JWK alg of the scheme used for use, preferring the signature scheme when use is empty
+func (ck *PublicKeyCrypto) jwkAlgorithm(use string) string {
+	switch use {
+	case jwkUseSig:
+		return ck.jwkSignatureAlg
+	case jwkUseEnc:
+		return ck.jwkEncryptionAlg
+	}
+	if ck.jwkSignatureAlg != "" {
+		return ck.jwkSignatureAlg
+	}
+	return ck.jwkEncryptionAlg
+}
+
+func (ck *PublicKeyCrypto) hasPrivateKey() bool {
+	return containsPrivateKey(ck.EncryptKey)
+}
+
+func containsPrivateKey(encryptkey *entity.EncryptKey) bool {
+	switch encryptkey.Keytype {
+	case entity.EncryptTypeRSA:
+		return encryptkey.RsaKey.PrivateKey != nil
+	case entity.EncryptTypeECDSA:
+		return encryptkey.EcdsaKey.PrivateKey != nil
+	case entity.EncryptTypeED25519:
+		return encryptkey.Ed25519Key.PrivateKey != nil
+	case entity.EncryptTypeX25519:
+		return encryptkey.X25519Key.PrivateKey != nil
+	default:
+		return false
+	}
+}
+
+func newPublicKeyCrypto(encryptkey entity.EncryptKey, opts []Option) (*PublicKeyCrypto, error) {
+	o := newOptions(opts)
+	pc := &PublicKeyCrypto{
+		EncryptKey: &encryptkey,
+		keyID:      o.keyID,
+		strictPEM:  o.strictPEM,
+	}
+	switch encryptkey.Keytype {
 	case entity.EncryptTypeRSA:
-		kid = parser.GenerateHashFromCrptoKey(ck.EncryptKey.RsaKey.PublicKey)
+		switch o.rsaPadding {
+		case RsaPaddingOAEP:
+			var err error
+			if o.rsaHybrid {
+				pc.encrypterRsa, err = encrypter.NewCryptoRsaHybrid(&encryptkey.RsaKey, o.oaepHash, o.oaepLabel)
+			} else {
+				pc.encrypterRsa, err = encrypter.NewCryptoRsaWithOAEP(&encryptkey.RsaKey, o.oaepHash, o.oaepLabel)
+			}
+			if err != nil {
+				return nil, err
+			}
+		case RsaPaddingPKCS1v15:
+			if o.rsaHybrid {
+				return nil, errors.New(errorInvalidRsaPadding)
+			}
+			pc.encrypterRsa = encrypter.NewCryptoRsa(&encryptkey.RsaKey)
+		default:
+			return nil, errors.New(errorInvalidRsaPadding)
+		}
+		var err error
+		if pc.signerRsa, err = signer.NewSignerRsa(&encryptkey.RsaKey, entity.RsaSignatureScheme(o.rsaSignature), o.signatureHash); err != nil {
+			return nil, err
+		}
 	case entity.EncryptTypeECDSA:
-		kid = parser.GenerateHashFromCrptoKey(ck.EncryptKey.EcdsaKey.PublicKey)
+		if o.eciesKDF != "" || o.eciesCipher != "" {
+			var err error
+			if pc.encrypterEcdsa, err = encrypter.NewCryptoEcdsaWithECIES(&encryptkey.EcdsaKey, entity.EciesKDF(o.eciesKDF), entity.EciesCipher(o.eciesCipher)); err != nil {
+				return nil, err
+			}
+		} else {
+			pc.encrypterEcdsa = encrypter.NewCryptoEcdsa(&encryptkey.EcdsaKey)
+		}
+		var err error
+		if pc.signerEcdsa, err = signer.NewSignerEcdsa(&encryptkey.EcdsaKey, entity.EcdsaSignatureFormat(o.ecdsaSignature), o.signatureHash); err != nil {
+			return nil, err
+		}
+	case entity.EncryptTypeED25519:
+		if o.ed25519Legacy {
+			pc.encrypterEd25519 = encrypter.NewCryptoEd25519WithLegacy(&encryptkey.Ed25519Key)
+		} else {
+			pc.encrypterEd25519 = encrypter.NewCryptoEd25519(&encryptkey.Ed25519Key)
+		}
+		pc.signerEd25519 = signer.NewSignerEd25519(&encryptkey.Ed25519Key)
+	case entity.EncryptTypeX25519:
+		pc.encrypterX25519 = encrypter.NewCryptoX25519(&encryptkey.X25519Key)
 	default:
-		return nil, errors.New(errorInvalidEncryptType)
+		return nil, errors.New(errorNoEncryptKeyType)
+	}
+	pc.jwkSignatureAlg, pc.jwkEncryptionAlg = jwkAlgorithms(&encryptkey, o)
+	if pc.keyID == "" {
+		var err error
+		if pc.keyID, err = generateKeyID(&encryptkey, o.keyIDStrategy); err != nil {
+			return nil, err
+		}
+	}
+	return pc, nil
+}
+
+// jwkAlgorithms returns the JWS and JWE alg defined in RFC 7518 matching the signature and encryption schemes of options.
+// Empty alg is returned for schemes without a matching alg, such as ECIES and hybrid RSA.
+func jwkAlgorithms(encryptkey *entity.EncryptKey, o *options) (string, string) {
+	hashSuffix := map[crypto.Hash]string{crypto.SHA256: "256", crypto.SHA384: "384", crypto.SHA512: "512"}
+	switch encryptkey.Keytype {
+	case entity.EncryptTypeRSA:
+		var signatureAlg, encryptionAlg string
+		if suffix, ok := hashSuffix[o.signatureHash]; ok {
+			if o.rsaSignature == RsaSignatureSchemePSS {
+				signatureAlg = "PS" + suffix
+			} else {
+				signatureAlg = "RS" + suffix
+			}
+		}
+		switch {
+		case o.rsaHybrid || len(o.oaepLabel) > 0:
+		case o.rsaPadding == RsaPaddingPKCS1v15:
+			encryptionAlg = "RSA1_5"
+		case o.oaepHash == crypto.SHA1:
+			encryptionAlg = "RSA-OAEP"
+		case o.oaepHash == crypto.SHA256:
+			encryptionAlg = "RSA-OAEP-256"
+		}
+		return signatureAlg, encryptionAlg
+	case entity.EncryptTypeECDSA:
+		curveHash := map[string]crypto.Hash{"P-256": crypto.SHA256, "P-384": crypto.SHA384, "P-521": crypto.SHA512}
+		if curveHash[encryptkey.EcdsaKey.PublicKey.Params().Name] == o.signatureHash {
+			return "ES" + hashSuffix[o.signatureHash], ""
+		}
+		return "", ""
+	case entity.EncryptTypeED25519:
+		return "EdDSA", ""
+	case entity.EncryptTypeX25519:
+		return "", "ECDH-ES"
+	default:
+		return "", ""
+	}
+}
+
+func generateKeyID(encryptkey *entity.EncryptKey, strategy KeyIDStrategy) (string, error) {
+	switch strategy {
+	case KeyIDStrategyThumbprintSHA256:
+		return parser.GenerateJWKThumbprint(encryptkey, crypto.SHA256)
+	case KeyIDStrategyThumbprintSHA512:
+		return parser.GenerateJWKThumbprint(encryptkey, crypto.SHA512)
+	case KeyIDStrategyLegacyMD5:
+		switch encryptkey.Keytype {
+		case entity.EncryptTypeRSA:
+			return parser.GenerateHashFromCrptoKey(encryptkey.RsaKey.PublicKey), nil
+		case entity.EncryptTypeECDSA:
+			return parser.GenerateHashFromCrptoKey(encryptkey.EcdsaKey.PublicKey), nil
+		case entity.EncryptTypeED25519:
+			return parser.GenerateHashFromCrptoKey(encryptkey.Ed25519Key.PublicKey), nil
+		case entity.EncryptTypeX25519:
+			return parser.GenerateHashFromCrptoKey(encryptkey.X25519Key.PublicKey.Bytes()), nil
+		default:
+			return "", errors.New(errorInvalidEncryptType)
+		}
+	default:
+		return "", errors.New(errorInvalidKeyID)
 	}
-	return parser.GenerateJSONWebKeyWithEncryptPublicKey(ck.EncryptKey, kid)
 }
 
 func generateEncryptKey(bits int, encryptType EncryptKeyType) (entity.EncryptKey, error) {
@@ -221,6 +862,13 @@ func generateEncryptKey(bits int, encryptType EncryptKeyType) (entity.EncryptKey
 		}
 		encryptkey.Ed25519Key.PublicKey = &publicKey
 		encryptkey.Ed25519Key.PrivateKey = &privateKey
+	case EncryptTypeX25519:
+		var err error
+		encryptkey.Keytype = entity.EncryptTypeX25519
+		encryptkey.X25519Key.PrivateKey, encryptkey.X25519Key.PublicKey, err = generator.GenerateX25519Keys()
+		if err != nil {
+			return encryptkey, err
+		}
 	}
 	return encryptkey, nil
 }
@@ -233,30 +881,65 @@ func generateKeyWithPEMPublicKey(publickey []byte) (entity.EncryptKey, error) {
 	return encryptkey, nil
 }
 
-func generateKeyWithJWKMPublicKey(publickey []byte, encryptType EncryptKeyType) (entity.EncryptKey, error) {
+func generateKeyWithPEMPrivateKey(privatekey []byte) (entity.EncryptKey, error) {
 	encryptkey := entity.EncryptKey{}
-	switch encryptType {
-	case EncryptTypeRSA:
-		encryptkey.Keytype = entity.EncryptTypeRSA
-		jwk, err := parser.ConvertToJSONWebKey([]byte(publickey))
-		if err != nil {
-			return encryptkey, err
-		}
-		if encryptkey.RsaKey.PublicKey, err = parser.ConvertToRSAPublicFromJWK(&jwk); err != nil {
-			return encryptkey, err
-		}
-		return encryptkey, nil
-	case EncryptTypeECDSA:
-		encryptkey.Keytype = entity.EncryptTypeECDSA
-		jwk, err := parser.ConvertToJSONWebKey([]byte(publickey))
-		if err != nil {
-			return encryptkey, err
+	if err := parser.DecodePrivateKey(privatekey, &encryptkey); err != nil {
+		return encryptkey, err
+	}
+	if err := setPublicKeyFromPrivateKey(&encryptkey); err != nil {
+		return encryptkey, err
+	}
+	return encryptkey, nil
+}
+
+// setPublicKeyFromPrivateKey fills the public half of encryptkey from its private key
+func setPublicKeyFromPrivateKey(encryptkey *entity.EncryptKey) error {
+	switch encryptkey.Keytype {
+	case entity.EncryptTypeRSA:
+		encryptkey.RsaKey.PublicKey = &encryptkey.RsaKey.PrivateKey.PublicKey
+	case entity.EncryptTypeECDSA:
+		encryptkey.EcdsaKey.PublicKey = &encryptkey.EcdsaKey.PrivateKey.PublicKey
+	case entity.EncryptTypeED25519:
+		publickey, ok := encryptkey.Ed25519Key.PrivateKey.Public().(ed25519.PublicKey)
+		if !ok {
+			return errors.New(errorInvalidEncryptType)
 		}
-		if encryptkey.EcdsaKey.PublicKey, err = parser.ConvertToEcdsaPublicFromJWK(&jwk); err != nil {
+		encryptkey.Ed25519Key.PublicKey = &publickey
+	case entity.EncryptTypeX25519:
+		encryptkey.X25519Key.PublicKey = encryptkey.X25519Key.PrivateKey.PublicKey()
+	default:
+		return errors.New(errorNoEncryptKeyType)
+	}
+	return nil
+}
+
+func generateKeyWithJWK(jwk []byte) (entity.EncryptKey, error) {
+	encryptkey := entity.EncryptKey{}
+	if err := parser.DecodeJWK(jwk, &encryptkey); err != nil {
+		return encryptkey, err
+	}
+	if containsPrivateKey(&encryptkey) {
+		if err := setPublicKeyFromPrivateKey(&encryptkey); err != nil {
 			return encryptkey, err
 		}
-		return encryptkey, nil
-	default:
+	}
+	return encryptkey, nil
+}
+
+func generateKeyWithJWKMPublicKey(publickey []byte, encryptType EncryptKeyType) (entity.EncryptKey, error) {
+	encryptkey, err := generateKeyWithJWK(publickey)
+	if err != nil {
+		return encryptkey, err
+	}
+	if EncryptKeyType(encryptkey.Keytype) != encryptType {
 		return encryptkey, errors.New(errorInvalidEncryptType)
 	}
+	// only the public key is used even if the JWK has the private key
+	return entity.EncryptKey{
+		Keytype:    encryptkey.Keytype,
+		RsaKey:     entity.RsaKey{PublicKey: encryptkey.RsaKey.PublicKey},
+		EcdsaKey:   entity.EcdsaKey{PublicKey: encryptkey.EcdsaKey.PublicKey},
+		Ed25519Key: entity.Ed25519Key{PublicKey: encryptkey.Ed25519Key.PublicKey},
+		X25519Key:  entity.X25519Key{PublicKey: encryptkey.X25519Key.PublicKey},
+	}, nil
 }